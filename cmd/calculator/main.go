@@ -17,13 +17,18 @@ package main
 import (
 	"context"
 	"flag"
+	"github.com/gorilla/mux"
 	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
 	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
 	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/alerting"
 	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
 	"github.com/project-alvarium/scoring-apps-go/internal/calculator"
 	"github.com/project-alvarium/scoring-apps-go/internal/calculator/policy"
+	"github.com/project-alvarium/scoring-apps-go/internal/calculator/types"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/metrics"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"github.com/project-alvarium/scoring-apps-go/pkg/policies"
 	"os"
 )
@@ -34,7 +39,7 @@ func main() {
 	flag.StringVar(&mode,
 		"mode",
 		"default",
-		"The policy mode of operation for the application.")
+		"The policy mode of operation for the application. Ignored when classifiers are set in the configuration.")
 
 	// Load config
 	var configPath string
@@ -65,38 +70,102 @@ func main() {
 	logger.Write(logging.DebugLevel, "config loaded successfully")
 	logger.Write(logging.DebugLevel, cfg.AsString())
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "calculator")
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Messages that need acknowledging are held until their key has been scored
+	pending := types.NewPendingAcks()
+	traces := types.NewKeyTraces()
 	chKeys := make(chan string)
-	sub, err := calculator.NewSubscriber(cfg.Stream.Subscribe, chKeys, logger)
+	sub, err := calculator.NewSubscriber(cfg.Stream.Subscribe, chKeys, pending, traces, logger)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
+	// Every component's state, and the dependencies it needs, are reported by the health endpoints
+	status := bootstrap.NewStatus()
+	status.AddCheck("broker", bootstrap.ConnectionCheck(sub.Connected))
+	dbCheck, err := calculator.NewArangoClient(cfg.Database, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	status.AddCheck("arango", dbCheck.Check)
+	handlers := []bootstrap.BootstrapHandler{status.Handler("subscriber", sub.BootstrapHandler)}
+
+	// Sharding is opt-in, a single replica owns every key
+	var membership *calculator.Membership
+	if cfg.Sharding.Enabled {
+		membership, err = calculator.NewMembership(cfg.Sharding, cfg.Database, logger)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		if err = membership.CheckStream(cfg.Stream.Subscribe); err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		handlers = append(handlers, status.Handler("membership", membership.BootstrapHandler))
+	}
+
 	chScore := make(chan string)
-	coll := calculator.NewCollector(chKeys, chScore, logger)
+	coll := calculator.NewCollector(chKeys, chScore, membership, pending, traces, logger)
+	handlers = append(handlers, status.Handler("collector", coll.BootstrapHandler))
 
-	p := policies.DcfPolicy{}
-	p.Name = mode
+	classifiers := cfg.Classifiers
+	if len(classifiers) == 0 {
+		classifiers = []string{mode}
+	}
 	provider, err := policy.NewPolicyProvider(cfg.Policy, logger)
 	if err != nil {
 		logger.Error(err.Error())
 		return
 	}
-	weights, err := provider.GetWeights(p.Name)
-	if err != nil {
-		logger.Error(err.Error())
-		return
+	if opa, ok := provider.(*policy.OpenPolicyProvider); ok {
+		status.AddCheck("opa", opa.Check)
+	}
+	var dcfPolicies []policies.DcfPolicy
+	for _, classifier := range classifiers {
+		weights, err := provider.GetWeights(classifier)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+		dcfPolicies = append(dcfPolicies, policies.DcfPolicy{Name: classifier, Weights: weights})
+	}
+
+	// Alerting is only enabled when at least one rule has been configured
+	var chAlerts chan alerting.Evaluation
+	if len(cfg.Alerting.Rules) > 0 {
+		chAlerts = make(chan alerting.Evaluation)
+		engine, err := alerting.NewEngine(cfg.Alerting, chAlerts, logger)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		handlers = append(handlers, status.Handler("alerting", engine.BootstrapHandler))
+	}
+
+	calc := calculator.NewCalculator(chScore, chAlerts, cfg.Database, pending, traces, logger, dcfPolicies)
+	handlers = append(handlers, status.Handler("calculator", calc.BootstrapHandler))
+
+	if cfg.Endpoint.Port > 0 {
+		r := mux.NewRouter()
+		calculator.LoadRestRoutes(r, &calc, provider, logger)
+		// The routes rely on the Calculator's database connection, so the server must start after it
+		server := bootstrap.NewHttpServer("Web", cfg.Endpoint.Port, r, logger)
+		handlers = append(handlers, status.Handler("http", server.BootstrapHandler))
 	}
-	p.Weights = weights
-	calc := calculator.NewCalculator(chScore, cfg.Database, logger, p)
+	handlers = append(handlers, metrics.NewHttpServer(cfg.Metrics, cfg.Health, status, logger).Handlers()...)
 	ctx, cancel := context.WithCancel(context.Background())
 	bootstrap.Run(
 		ctx,
 		cancel,
 		cfg,
-		[]bootstrap.BootstrapHandler{
-			sub.BootstrapHandler,
-			coll.BootstrapHandler,
-			calc.BootstrapHandler,
-		})
+		handlers)
 }
@@ -0,0 +1,230 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/factories"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"os"
+	"time"
+)
+
+const usage = `Usage: deadletter [-cfg path] [-id key] <command>
+
+Inspects and re-drives the messages the subscriber failed to ingest. The subscriber's own config file is used.
+
+Commands:
+  list      print dead letters as JSON, one per line
+  redrive   ingest dead letters again, removing the ones that succeed
+  purge     remove dead letters without ingesting them
+`
+
+func main() {
+	var configPath, id string
+	flag.StringVar(&configPath,
+		"cfg",
+		"./res/config.json",
+		"Path to the subscriber's JSON configuration file.")
+	flag.StringVar(&id,
+		"id",
+		"",
+		"Only act on the dead letter with this key.")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fileFormat := config.GetFileExtension(configPath)
+	reader, err := config.NewReader(fileFormat)
+	if err != nil {
+		tmpLog := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+		tmpLog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	cfg := subscriber.ApplicationConfig{}
+	err = reader.Read(configPath, &cfg)
+	if err != nil {
+		tmpLog := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+		tmpLog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	logger := logFactory.NewLogger(cfg.Logging)
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "deadletter")
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	store, err := deadletter.NewStore(cfg.DeadLetter, cfg.Database, logger)
+	if err == nil && store == nil {
+		err = errors.New("dead-lettering is not configured")
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	letters, err := store.List(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	if len(id) > 0 {
+		letters = filter(letters, id)
+	}
+
+	switch flag.Arg(0) {
+	case "list":
+		for _, l := range letters {
+			b, _ := json.Marshal(l)
+			fmt.Println(string(b))
+		}
+	case "redrive":
+		err = redrive(ctx, cfg, store, letters, logger)
+	case "purge":
+		purged := 0
+		for _, l := range letters {
+			if err = store.Remove(ctx, l.Key); err != nil {
+				break
+			}
+			purged++
+		}
+		logger.Write(logging.InfoLevel, fmt.Sprintf("purged %v of %v dead letters", purged, len(letters)))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// redrive pushes each dead letter back through the subscriber's ingestion so the calculator is notified exactly as it
+// would have been originally. A letter is only removed once it has been ingested and its key published, letters that
+// fail again stay in the store with their attempt count increased. Ingestion overwrites what it has already written, so
+// a letter whose key couldn't be published is safe to re-drive again.
+func redrive(ctx context.Context, cfg subscriber.ApplicationConfig, store deadletter.Store, letters []documents.DeadLetter,
+	logger logInterface.Logger) error {
+	pub, err := factories.NewPublisher(cfg.Stream.Publish)
+	if err != nil {
+		return err
+	}
+	defer pub.Close()
+
+	// Keys requested by ingestion are collected, then taken and published once the letter's ingestion has returned
+	chKeys := make(chan subscriber.ScoreRequest)
+	chTake := make(chan []subscriber.ScoreRequest)
+	defer close(chKeys)
+	go func() {
+		var keys []subscriber.ScoreRequest
+		for {
+			select {
+			case key, ok := <-chKeys:
+				if !ok {
+					return
+				}
+				keys = append(keys, key)
+			case chTake <- keys:
+				keys = nil
+			}
+		}
+	}()
+
+	// Letters are validated as they would have been when received from their stream
+	sources, err := cfg.Sources()
+	if err != nil {
+		return err
+	}
+	validators := subscriber.NewValidators(cfg.Validation, sources, logger)
+	graph, err := subscriber.NewArangoClient(nil, chKeys, cfg.Database, cfg.Retry, validators, nil, logger)
+	if err != nil {
+		return err
+	}
+
+	redriven := 0
+	for _, l := range letters {
+		if len(l.Raw) > 0 {
+			logger.Write(logging.InfoLevel, fmt.Sprintf("skipping %s, its payload was never a valid message", l.Key))
+			continue
+		}
+		err = graph.Ingest(ctx, subscriber.Message{SubscribeWrapper: l.Message, Source: l.Source})
+		keys := <-chTake
+		if err == nil {
+			err = publish(ctx, pub, keys)
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("%s failed again: %s", l.Key, err.Error()))
+			l.Error = err.Error()
+			l.Attempts++
+			l.Timestamp = time.Now()
+			err = store.Write(ctx, l)
+		} else {
+			redriven++
+			err = store.Remove(ctx, l.Key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	logger.Write(logging.InfoLevel, fmt.Sprintf("redrove %v of %v dead letters", redriven, len(letters)))
+	return nil
+}
+
+// publish requests the keys be scored, continuing the trace of their ingestion
+func publish(ctx context.Context, pub interfaces.Publisher, keys []subscriber.ScoreRequest) error {
+	for _, key := range keys {
+		toSend := msg.NewPublishWrapper("CalculateScore", msg.ContentTypeText, "deadletter", []byte(key.Key))
+		toSend.Headers = key.Trace
+		err := pub.Publish(ctx, toSend)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func filter(letters []documents.DeadLetter, key string) []documents.DeadLetter {
+	for _, l := range letters {
+		if l.Key == key {
+			return []documents.DeadLetter{l}
+		}
+	}
+	return nil
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/db"
+	"github.com/project-alvarium/scoring-apps-go/internal/metrics"
 	populator_api "github.com/project-alvarium/scoring-apps-go/internal/populator-api"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"os"
 )
 
@@ -57,6 +59,13 @@ func main() {
 	logger.Write(logging.DebugLevel, "config loaded successfully")
 	logger.Write(logging.DebugLevel, cfg.AsString())
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "populator-api")
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	dbMongo, err := db.NewMongoProvider(cfg.Databases, logger)
 	if err != nil {
 		logger.Error(err.Error())
@@ -71,12 +80,22 @@ func main() {
 
 	r := mux.NewRouter()
 	populator_api.LoadRestRoutes(r, dbArango, dbMongo, logger)
+	status := bootstrap.NewStatus()
+	status.AddCheck("arango", dbArango.Check)
+	status.AddCheck("mongo", dbMongo.Check)
+	handlers := []bootstrap.BootstrapHandler{
+		status.Handler("http", bootstrap.NewHttpServer("Web", cfg.Endpoint.Port, r, logger).BootstrapHandler),
+	}
+	handlers = append(handlers, metrics.NewHttpServer(cfg.Metrics, cfg.Health, status, logger).Handlers()...)
 	ctx, cancel := context.WithCancel(context.Background())
 	bootstrap.Run(
 		ctx,
 		cancel,
 		cfg,
-		[]bootstrap.BootstrapHandler{
-			populator_api.NewHttpServer(r, cfg.Endpoint, dbMongo, logger).BootstrapHandler,
-		})
+		handlers)
+
+	err = dbMongo.Close(context.Background())
+	if err != nil {
+		logger.Error(err.Error())
+	}
 }
@@ -23,7 +23,9 @@ import (
 	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/db"
+	"github.com/project-alvarium/scoring-apps-go/internal/metrics"
 	"github.com/project-alvarium/scoring-apps-go/internal/populator"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"os"
 )
 
@@ -56,6 +58,13 @@ func main() {
 	logger.Write(logging.DebugLevel, "config loaded successfully")
 	logger.Write(logging.DebugLevel, cfg.AsString())
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "populator")
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	dbMongo, err := db.NewMongoProvider(cfg.Databases, logger)
 	if err != nil {
 		logger.Error(err.Error())
@@ -68,13 +77,16 @@ func main() {
 		os.Exit(-1)
 	}
 
-	worker := populator.NewWorker(dbArango, dbMongo, logger)
+	worker := populator.NewWorker(cfg.Classifier, dbArango, dbMongo, logger)
+	status := bootstrap.NewStatus()
+	status.AddCheck("arango", dbArango.Check)
+	status.AddCheck("mongo", dbMongo.Check)
+	handlers := []bootstrap.BootstrapHandler{status.Handler("worker", worker.BootstrapHandler)}
+	handlers = append(handlers, metrics.NewHttpServer(cfg.Metrics, cfg.Health, status, logger).Handlers()...)
 	ctx, cancel := context.WithCancel(context.Background())
 	bootstrap.Run(
 		ctx,
 		cancel,
 		cfg,
-		[]bootstrap.BootstrapHandler{
-			worker.BootstrapHandler,
-		})
+		handlers)
 }
@@ -17,14 +17,18 @@ package main
 import (
 	"context"
 	"flag"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	"fmt"
 	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
 	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
 	"github.com/project-alvarium/provider-logging/pkg/logging"
 	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	"github.com/project-alvarium/scoring-apps-go/internal/metrics"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
 	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
 	"github.com/project-alvarium/scoring-apps-go/internal/subscriber/streams"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"os"
 )
 
@@ -57,31 +61,80 @@ func main() {
 	logger.Write(logging.DebugLevel, "config loaded successfully")
 	logger.Write(logging.DebugLevel, cfg.AsString())
 
-	chMessages := make(chan message.SubscribeWrapper)
-	sub, err := streams.NewSubscriber(cfg.Sdk.Stream, chMessages, cfg.Key, logger)
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "subscriber")
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	deadLetters, err := deadletter.NewStore(cfg.DeadLetter, cfg.Database, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	chMessages := make(chan subscriber.Message)
+	chStream := chMessages
+	var recorder *subscriber.Recorder
+	if len(cfg.Recording.Path) > 0 {
+		// The stream feeds the recorder, which passes each message on to ingestion
+		chStream = make(chan subscriber.Message)
+		r := subscriber.NewRecorder(cfg.Recording.Path, chStream, chMessages, logger)
+		recorder = &r
+	}
+
+	sources, err := cfg.Sources()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	// Every stream feeds the fan-in, which tags messages with their source
+	fanIn := subscriber.NewFanIn(chStream, logger)
+	// Every component's state, and the dependencies it needs, are reported by the health endpoints
+	status := bootstrap.NewStatus()
+	var handlers []bootstrap.BootstrapHandler
+	for _, source := range sources {
+		sub, err := streams.NewSubscriber(source.Stream, fanIn.Add(source.Name), cfg.Key, deadLetters, logger)
+		if err != nil {
+			logger.Error(fmt.Sprintf("stream %s: %s", source.Name, err.Error()))
+			os.Exit(1)
+		}
+		if h, ok := sub.(interfaces.Health); ok {
+			status.AddCheck("stream "+source.Name, bootstrap.ConnectionCheck(h.Connected))
+		}
+		handlers = append(handlers, status.Handler("stream "+source.Name, sub.Subscribe))
+	}
+	handlers = append(handlers, status.Handler("fan-in", fanIn.BootstrapHandler))
 
-	chKeys := make(chan string)
-	graph, err := subscriber.NewArangoClient(chMessages, chKeys, cfg.Database, logger)
+	chKeys := make(chan subscriber.ScoreRequest)
+	validators := subscriber.NewValidators(cfg.Validation, sources, logger)
+	graph, err := subscriber.NewArangoClient(chMessages, chKeys, cfg.Database, cfg.Retry, validators, deadLetters, logger)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
-	pub, err := subscriber.NewPublisher(cfg.Stream.Publish, chKeys, logger)
+	pub, err := subscriber.NewPublisher(cfg.Stream.Publish, cfg.Outbox, chKeys, logger)
 	if err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
+	status.AddCheck("arango", graph.Check)
+	status.AddCheck("publisher", pub.Check)
+	if recorder != nil {
+		handlers = append(handlers, status.Handler("recorder", recorder.BootstrapHandler))
+	}
+	handlers = append(handlers, status.Handler("ingestion", graph.BootstrapHandler), status.Handler("publisher", pub.BootstrapHandler))
+	handlers = append(handlers, metrics.NewHttpServer(cfg.Metrics, cfg.Health, status, logger).Handlers()...)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	bootstrap.Run(
 		ctx,
 		cancel,
 		cfg,
-		[]bootstrap.BootstrapHandler{
-			sub.Subscribe,
-			graph.BootstrapHandler,
-			pub.BootstrapHandler,
-		})
+		handlers)
 	logger.Write(logging.InfoLevel, "exiting...")
 }
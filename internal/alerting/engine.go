@@ -0,0 +1,291 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package alerting
+
+import (
+	"context"
+	"fmt"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"sync"
+	"time"
+)
+
+// defaultCooldown is applied to rules that do not define their own. Without it a key that is rescored as further
+// annotations arrive would raise the same alert every time.
+const defaultCooldown int64 = 60000
+
+const (
+	dispatchBuffer int           = 100         // dispatchBuffer is the number of alerts that may wait for the sinks
+	pruneInterval  time.Duration = time.Minute // pruneInterval is how often failures of hosts that stopped failing are dropped
+)
+
+// Engine evaluates every Evaluation received on its channel against the configured rules and dispatches the
+// resulting alerts to the relevant sinks.
+type Engine struct {
+	chDispatch chan Alert
+	chEvaluate chan Evaluation
+	failures   map[string]*hostFailures // failures tracks unsatisfied annotations per host rule and host
+	fired      map[string]time.Time     // fired tracks when the cooldown expires per rule and subject
+	logger     logInterface.Logger
+	mutex      sync.Mutex
+	rules      []config.AlertRule
+	sinks      map[string]Sink
+}
+
+func NewEngine(cfg config.AlertingInfo, chEvaluate chan Evaluation, logger logInterface.Logger) (*Engine, error) {
+	e := Engine{
+		chDispatch: make(chan Alert, dispatchBuffer),
+		chEvaluate: chEvaluate,
+		failures:   make(map[string]*hostFailures),
+		fired:      make(map[string]time.Time),
+		logger:     logger,
+		rules:      cfg.Rules,
+		sinks:      make(map[string]Sink),
+	}
+
+	for _, info := range cfg.Sinks {
+		if _, ok := e.sinks[info.Name]; ok {
+			return nil, fmt.Errorf("duplicate alert sink name %s", info.Name)
+		}
+		s, err := NewSink(info, logger)
+		if err != nil {
+			return nil, err
+		}
+		e.sinks[info.Name] = s
+	}
+
+	for _, r := range cfg.Rules {
+		for _, name := range r.Sinks {
+			if _, ok := e.sinks[name]; !ok {
+				return nil, fmt.Errorf("alert rule %s references undefined sink %s", r.Name, name)
+			}
+		}
+	}
+	return &e, nil
+}
+
+func (e *Engine) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(e.chDispatch)
+
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case ev, ok := <-e.chEvaluate:
+				if !ok {
+					return
+				}
+				e.enqueue(e.evaluate(ev, time.Now()))
+			case now := <-ticker.C:
+				e.pruneFailures(now)
+			case <-ctx.Done():
+				e.logger.Write(logging.InfoLevel, "shutdown received")
+				return
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() { // Sinks are called separately so that a slow sink never holds up scoring
+		defer wg.Done()
+
+		for a := range e.chDispatch {
+			e.dispatch(ctx, a)
+		}
+		for _, s := range e.sinks {
+			s.Close()
+		}
+	}()
+	return true
+}
+
+// evaluate returns the alerts raised by the given Evaluation, after deduplication and cooldowns have been applied.
+func (e *Engine) evaluate(ev Evaluation, now time.Time) []Alert {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var alerts []Alert
+	for _, r := range e.rules {
+		for _, a := range e.match(r, ev, now) {
+			if e.coolingDown(r, a.Subject, now) {
+				continue
+			}
+			if r.Type == config.RuleHost {
+				// Start counting again once the alert is sent, so the next alert requires another Count failures.
+				// Failures seen while cooling down are kept, so they still count once the cooldown has expired.
+				delete(e.failures, r.Name+"/"+a.Subject)
+			}
+			a.Rule = r.Name
+			a.Type = r.Type
+			a.DataRef = ev.Score.DataRef
+			a.Confidence = ev.Score.Confidence
+			a.Timestamp = now
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+// match returns one Alert per distinct subject for which the rule's condition holds.
+func (e *Engine) match(r config.AlertRule, ev Evaluation, now time.Time) []Alert {
+	switch r.Type {
+	case config.RuleConfidence:
+		if ev.Score.Confidence < r.Threshold {
+			return []Alert{{
+				Subject: ev.Score.DataRef,
+				Message: fmt.Sprintf("confidence %v is below threshold %v", ev.Score.Confidence, r.Threshold),
+			}}
+		}
+	case config.RuleAnnotation:
+		for _, a := range ev.Annotations {
+			if a.Kind == r.Kind && !a.IsSatisfied {
+				return []Alert{{
+					Subject: ev.Score.DataRef,
+					Message: fmt.Sprintf("annotation %s from host %s was not satisfied", a.Kind, a.Host),
+				}}
+			}
+		}
+	case config.RuleHost:
+		var alerts []Alert
+		matched := make(map[string]bool)
+		for _, a := range ev.Annotations {
+			if a.IsSatisfied || (len(r.Host) > 0 && a.Host != r.Host) || matched[a.Host] {
+				continue
+			}
+			key := r.Name + "/" + a.Host
+			f, ok := e.failures[key]
+			if !ok {
+				f = &hostFailures{window: r.Window, times: make(map[string]time.Time)}
+				e.failures[key] = f
+			}
+			f.prune(now)
+			// A key is rescored as each annotation arrives, so the same failure is seen many times
+			if _, counted := f.times[annotationKey(a)]; counted {
+				continue
+			}
+			f.times[annotationKey(a)] = now
+			if len(f.times) >= r.Count {
+				matched[a.Host] = true
+				alerts = append(alerts, Alert{
+					Subject: a.Host,
+					Message: fmt.Sprintf("host %s failed %v annotations within %vms", a.Host, len(f.times), r.Window),
+				})
+			}
+		}
+		return alerts
+	}
+	return nil
+}
+
+// coolingDown reports whether an alert for the rule and subject was sent recently, and records this one otherwise.
+func (e *Engine) coolingDown(r config.AlertRule, subject string, now time.Time) bool {
+	cooldown := r.Cooldown
+	if cooldown <= 0 {
+		cooldown = defaultCooldown
+	}
+
+	// Expired entries no longer matter, drop them so the map doesn't grow unbounded.
+	for k, expires := range e.fired {
+		if !now.Before(expires) {
+			delete(e.fired, k)
+		}
+	}
+
+	key := r.Name + "/" + subject
+	if _, ok := e.fired[key]; ok {
+		return true
+	}
+	e.fired[key] = now.Add(time.Millisecond * time.Duration(cooldown))
+	return false
+}
+
+// pruneFailures drops the failures that have fallen outside of their window, along with hosts that have none left
+func (e *Engine) pruneFailures(now time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for k, f := range e.failures {
+		f.prune(now)
+		if len(f.times) == 0 {
+			delete(e.failures, k)
+		}
+	}
+}
+
+// enqueue hands alerts to the dispatch goroutine. Alerts are dropped rather than waited on when the sinks have
+// fallen too far behind.
+func (e *Engine) enqueue(alerts []Alert) {
+	for _, a := range alerts {
+		select {
+		case e.chDispatch <- a:
+		default:
+			e.logger.Error(fmt.Sprintf("alert queue is full, dropped %s alert for %s", a.Rule, a.Subject))
+		}
+	}
+}
+
+func (e *Engine) dispatch(ctx context.Context, a Alert) {
+	for _, name := range e.sinksFor(a.Rule) {
+		err := e.sinks[name].Send(ctx, a)
+		if err != nil {
+			e.logger.Error(fmt.Sprintf("alert sink %s failed: %s", name, err.Error()))
+		}
+	}
+}
+
+func (e *Engine) sinksFor(rule string) []string {
+	for _, r := range e.rules {
+		if r.Name == rule && len(r.Sinks) > 0 {
+			return r.Sinks
+		}
+	}
+	var names []string
+	for k := range e.sinks {
+		names = append(names, k)
+	}
+	return names
+}
+
+// hostFailures are the unsatisfied annotations counted towards a host rule, by annotation key
+type hostFailures struct {
+	window int64
+	times  map[string]time.Time
+}
+
+// prune removes the failures that have fallen outside of the window. A window of zero keeps everything.
+func (f *hostFailures) prune(now time.Time) {
+	if f.window <= 0 {
+		return
+	}
+	for k, t := range f.times {
+		if now.Sub(t).Milliseconds() >= f.window {
+			delete(f.times, k)
+		}
+	}
+}
+
+// annotationKey identifies an annotation across evaluations, falling back to its data and kind when it has no key
+func annotationKey(a documents.Annotation) string {
+	if len(a.Key) > 0 {
+		return a.Key
+	}
+	return a.DataRef + "/" + a.Kind
+}
@@ -0,0 +1,147 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package alerting
+
+import (
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"testing"
+	"time"
+)
+
+func TestEngineEvaluate(t *testing.T) {
+	failedTpm := documents.Annotation{Kind: "tpm", Host: "host-a", IsSatisfied: false}
+	passedTls := documents.Annotation{Kind: "tls", Host: "host-a", IsSatisfied: true}
+
+	tests := []struct {
+		name        string
+		rule        config.AlertRule
+		evaluation  Evaluation
+		expectCount int
+	}{
+		{"confidence below threshold",
+			config.AlertRule{Name: "low", Type: config.RuleConfidence, Threshold: 0.5},
+			Evaluation{Score: documents.Score{DataRef: "k1", Confidence: 0.3}}, 1},
+		{"confidence above threshold",
+			config.AlertRule{Name: "low", Type: config.RuleConfidence, Threshold: 0.5},
+			Evaluation{Score: documents.Score{DataRef: "k1", Confidence: 0.8}}, 0},
+		{"annotation kind failed",
+			config.AlertRule{Name: "tpm", Type: config.RuleAnnotation, Kind: "tpm"},
+			Evaluation{Score: documents.Score{DataRef: "k1"}, Annotations: []documents.Annotation{failedTpm, passedTls}}, 1},
+		{"annotation kind satisfied",
+			config.AlertRule{Name: "tls", Type: config.RuleAnnotation, Kind: "tls"},
+			Evaluation{Score: documents.Score{DataRef: "k1"}, Annotations: []documents.Annotation{failedTpm, passedTls}}, 0},
+		{"host failure count reached",
+			config.AlertRule{Name: "host", Type: config.RuleHost, Count: 1},
+			Evaluation{Score: documents.Score{DataRef: "k1"}, Annotations: []documents.Annotation{failedTpm}}, 1},
+		{"host failure for another host",
+			config.AlertRule{Name: "host", Type: config.RuleHost, Count: 1, Host: "host-b"},
+			Evaluation{Score: documents.Score{DataRef: "k1"}, Annotations: []documents.Annotation{failedTpm}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(tt.rule)
+			alerts := e.evaluate(tt.evaluation, time.Now())
+			if len(alerts) != tt.expectCount {
+				t.Errorf("expected %v alerts, received %v", tt.expectCount, len(alerts))
+			}
+		})
+	}
+}
+
+func TestEngineCooldown(t *testing.T) {
+	e := newTestEngine(config.AlertRule{Name: "low", Type: config.RuleConfidence, Threshold: 0.5, Cooldown: 1000})
+	ev := Evaluation{Score: documents.Score{DataRef: "k1", Confidence: 0.1}}
+	now := time.Now()
+
+	if len(e.evaluate(ev, now)) != 1 {
+		t.Fatalf("expected first evaluation to raise an alert")
+	}
+	if len(e.evaluate(ev, now.Add(500*time.Millisecond))) != 0 {
+		t.Errorf("expected repeat alert to be suppressed during cooldown")
+	}
+	other := Evaluation{Score: documents.Score{DataRef: "k2", Confidence: 0.1}}
+	if len(e.evaluate(other, now.Add(500*time.Millisecond))) != 1 {
+		t.Errorf("expected alert for a different subject during cooldown")
+	}
+	if len(e.evaluate(ev, now.Add(1500*time.Millisecond))) != 1 {
+		t.Errorf("expected alert once cooldown expired")
+	}
+}
+
+func TestEngineHostWindow(t *testing.T) {
+	e := newTestEngine(config.AlertRule{Name: "host", Type: config.RuleHost, Count: 3, Window: 1000, Cooldown: 1})
+	failure := func(key string) Evaluation {
+		return Evaluation{Annotations: []documents.Annotation{{Key: key, Kind: "tpm", Host: "host-a"}}}
+	}
+	now := time.Now()
+
+	e.evaluate(failure("a1"), now)
+	e.evaluate(failure("a2"), now.Add(100*time.Millisecond))
+	// The first failure falls out of the window, so this is only the second within it
+	if len(e.evaluate(failure("a3"), now.Add(1050*time.Millisecond))) != 0 {
+		t.Errorf("expected failures outside of the window to be ignored")
+	}
+	if len(e.evaluate(failure("a4"), now.Add(1060*time.Millisecond))) != 1 {
+		t.Errorf("expected alert once count reached within window")
+	}
+
+	// The same failed annotation is seen again each time its key is rescored, and by each policy
+	e.evaluate(failure("a5"), now.Add(1100*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		if len(e.evaluate(failure("a5"), now.Add(1200*time.Millisecond))) != 0 {
+			t.Errorf("expected a repeated failure to be counted once")
+		}
+	}
+
+	e.pruneFailures(now.Add(3000 * time.Millisecond))
+	if len(e.failures) != 0 {
+		t.Errorf("expected hosts without recent failures to be pruned, %v remain", len(e.failures))
+	}
+}
+
+func TestEngineHostCooldown(t *testing.T) {
+	e := newTestEngine(config.AlertRule{Name: "host", Type: config.RuleHost, Count: 2, Window: 10000, Cooldown: 1000})
+	failure := func(key string) Evaluation {
+		return Evaluation{Annotations: []documents.Annotation{{Key: key, Kind: "tpm", Host: "host-a"}}}
+	}
+	now := time.Now()
+
+	e.evaluate(failure("a1"), now)
+	if len(e.evaluate(failure("a2"), now.Add(100*time.Millisecond))) != 1 {
+		t.Fatalf("expected alert once count reached")
+	}
+	// Failures during the cooldown raise no alert, but still count towards the next one
+	e.evaluate(failure("a3"), now.Add(200*time.Millisecond))
+	if len(e.evaluate(failure("a4"), now.Add(300*time.Millisecond))) != 0 {
+		t.Errorf("expected alert to be suppressed during cooldown")
+	}
+	if len(e.evaluate(failure("a5"), now.Add(1200*time.Millisecond))) != 1 {
+		t.Errorf("expected failures seen during the cooldown to raise an alert once it expired")
+	}
+	// The alert was sent, so counting starts again
+	if len(e.evaluate(failure("a6"), now.Add(2300*time.Millisecond))) != 0 {
+		t.Errorf("expected count to restart after the alert")
+	}
+}
+
+func newTestEngine(rule config.AlertRule) *Engine {
+	return &Engine{
+		failures: make(map[string]*hostFailures),
+		fired:    make(map[string]time.Time),
+		rules:    []config.AlertRule{rule},
+		sinks:    make(map[string]Sink),
+	}
+}
@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/factories"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"net/http"
+	"time"
+)
+
+const (
+	alertMessageType      string = "ConfidenceAlert"
+	alertProducer         string = "alerting"
+	defaultWebhookTimeout int64  = 5000
+)
+
+func NewSink(info config.AlertSinkInfo, logger logInterface.Logger) (Sink, error) {
+	switch info.Type {
+	case config.SinkLog:
+		return &logSink{logger: logger}, nil
+	case config.SinkMqtt:
+		cfg, ok := info.Config.(config.MqttConfig)
+		if !ok {
+			return nil, errors.New("invalid cast for mqtt sink config")
+		}
+		publisher, err := factories.NewPublisher(config.StreamInfo{Type: config.MqttStream, Config: cfg})
+		if err != nil {
+			return nil, err
+		}
+		return &mqttSink{publisher: publisher}, nil
+	case config.SinkWebhook:
+		cfg, ok := info.Config.(config.WebhookSinkConfig)
+		if !ok {
+			return nil, errors.New("invalid cast for webhook sink config")
+		}
+		if len(cfg.Url) == 0 {
+			return nil, fmt.Errorf("webhook sink %s requires a url", info.Name)
+		}
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = defaultWebhookTimeout
+		}
+		return &webhookSink{
+			cfg:    cfg,
+			client: &http.Client{Timeout: time.Millisecond * time.Duration(timeout)},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized alert sink type %s", info.Type)
+	}
+}
+
+// logSink writes alerts to the application log. It is useful as a default and for debugging rule definitions.
+type logSink struct {
+	logger logInterface.Logger
+}
+
+func (s *logSink) Send(ctx context.Context, alert Alert) error {
+	b, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	s.logger.Error("confidence alert " + string(b))
+	return nil
+}
+
+func (s *logSink) Close() error {
+	return nil
+}
+
+// mqttSink publishes alerts to the configured topics using the same publisher the subscriber uses for keys.
+type mqttSink struct {
+	publisher interfaces.Publisher
+}
+
+func (s *mqttSink) Send(ctx context.Context, alert Alert) error {
+	return s.publisher.Publish(ctx, msg.NewPublishWrapper(alertMessageType, msg.ContentTypeJson, alertProducer, alert))
+}
+
+func (s *mqttSink) Close() error {
+	return s.publisher.Close()
+}
+
+// webhookSink POSTs each alert as JSON to the configured URL.
+type webhookSink struct {
+	cfg    config.WebhookSinkConfig
+	client *http.Client
+}
+
+func (s *webhookSink) Send(ctx context.Context, alert Alert) error {
+	b, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook %s returned status %v", s.cfg.Url, resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *webhookSink) Close() error {
+	return nil
+}
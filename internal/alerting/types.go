@@ -0,0 +1,50 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+/*
+Package alerting evaluates newly calculated scores against a configurable set of rules and notifies the
+configured sinks when a rule matches.
+*/
+package alerting
+
+import (
+	"context"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"time"
+)
+
+// Evaluation is handed to the Engine by the calculator for every score it persists. The annotations are the same
+// ones the score was calculated from.
+type Evaluation struct {
+	Score       documents.Score
+	Annotations []documents.Annotation
+}
+
+// Alert is the payload delivered to every sink when a rule matches.
+type Alert struct {
+	Rule       string               `json:"rule"`                // Rule is the name of the matching rule
+	Type       config.AlertRuleType `json:"type"`                // Type is the type of the matching rule
+	Subject    string               `json:"subject"`             // Subject is what the alert is about, either a data key or a host
+	DataRef    string               `json:"dataRef,omitempty"`   // DataRef is the key of the data whose score triggered the alert
+	Confidence float64              `json:"confidence"`          // Confidence is the confidence of the triggering score
+	Message    string               `json:"message,omitempty"`   // Message is a human readable description of the alert
+	Timestamp  time.Time            `json:"timestamp,omitempty"` // Timestamp indicates when the alert was raised
+}
+
+// Sink is the contract each alert destination must fulfill.
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+	Close() error
+}
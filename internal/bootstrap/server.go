@@ -0,0 +1,93 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package bootstrap
+
+import (
+	"context"
+	"github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HttpServer serves a handler on the given port until the application shuts down. It is shared by every application
+// that exposes an HTTP API.
+type HttpServer struct {
+	handler http.Handler
+	logger  interfaces.Logger
+	name    string
+	port    int
+}
+
+// NewHttpServer is a factory method that returns an initialized HttpServer receiver struct. The name identifies the
+// server in the log.
+func NewHttpServer(name string, port int, handler http.Handler, logger interfaces.Logger) *HttpServer {
+	return &HttpServer{
+		handler: handler,
+		logger:  logger,
+		name:    name,
+		port:    port,
+	}
+}
+
+// BootstrapHandler fulfills the BootstrapHandler contract. It binds the port before returning, so a port that is
+// already in use fails the bootstrap, then creates two go routines -- one that serves requests on the listener and
+// another that waits on closure of a context's done channel before calling Shutdown() to cleanly shut down the http
+// server.
+func (b *HttpServer) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool {
+	addr := ":" + strconv.Itoa(b.port)
+
+	timeout := time.Millisecond * 10000
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      b.handler,
+		WriteTimeout: timeout,
+		ReadTimeout:  timeout,
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		b.logger.Error(err.Error())
+		return false
+	}
+	b.logger.Write(logging.InfoLevel, b.name+" server starting ("+addr+")")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			b.logger.Error(err.Error())
+			Fail(ctx, err)
+		}
+		b.logger.Write(logging.InfoLevel, b.name+" server stopped")
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		<-ctx.Done()
+		b.logger.Write(logging.InfoLevel, b.name+" server shutting down")
+		// ctx is already cancelled, so requests in flight are given a context of their own to complete
+		_ = server.Shutdown(context.Background())
+	}()
+
+	return true
+}
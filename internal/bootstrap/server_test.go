@@ -0,0 +1,49 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package bootstrap
+
+import (
+	"context"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestHttpServerBind(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	server := NewHttpServer("Test", port, http.NotFoundHandler(), logger)
+	if server.BootstrapHandler(ctx, &wg) {
+		t.Errorf("expected bootstrap to fail while the port is in use")
+	}
+
+	listener.Close()
+	if !server.BootstrapHandler(ctx, &wg) {
+		t.Errorf("expected bootstrap to succeed once the port is free")
+	}
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,215 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// State is the lifecycle state of a component started by a BootstrapHandler
+type State string
+
+const (
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateFailed   State = "failed"
+	StateStopped  State = "stopped"
+)
+
+const checkTimeout = 2 * time.Second
+
+// Check reports whether a dependency of the application can currently be reached
+type Check func(ctx context.Context) error
+
+// ConnectionCheck adapts a client that tracks its own connection state, see interfaces.Health, to a Check.
+func ConnectionCheck(connected func() bool) Check {
+	return func(ctx context.Context) error {
+		if !connected() {
+			return errors.New("not connected")
+		}
+		return nil
+	}
+}
+
+// ComponentReport is the state of a single component
+type ComponentReport struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// CheckReport is the outcome of a single dependency check
+type CheckReport struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// Report summarizes the state of the application. Healthy is false when a component, or for readiness a check, is
+// not in the state required.
+type Report struct {
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentReport `json:"components"`
+	Checks     []CheckReport     `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Status tracks the state of each component started by the bootstrap process, along with the checks of the
+// dependencies the application needs before it can do any work.
+type Status struct {
+	checks     []namedCheck
+	components []*ComponentReport // components are kept in the order they were added
+	mutex      sync.RWMutex
+}
+
+func NewStatus() *Status {
+	return &Status{}
+}
+
+// AddCheck registers a dependency that must be reachable for the application to be ready
+func (s *Status) AddCheck(name string, check Check) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.checks = append(s.checks, namedCheck{name: name, check: check})
+}
+
+// Handler wraps h so that the state of the component it starts is tracked under name. The component is running once
+// h returns true, and has failed if h returns false or if the component calls Fail with the context it was given.
+func (s *Status) Handler(name string, h BootstrapHandler) BootstrapHandler {
+	c := &ComponentReport{Name: name, State: StateStarting}
+	s.mutex.Lock()
+	s.components = append(s.components, c)
+	s.mutex.Unlock()
+
+	return func(ctx context.Context, wg *sync.WaitGroup) bool {
+		var inner sync.WaitGroup
+		failCtx := context.WithValue(ctx, failKey{}, func(err error) {
+			s.set(c, StateFailed, err.Error())
+		})
+		ok := h(failCtx, &inner)
+		if ok {
+			// The component may already have failed before h returned
+			s.advance(c, StateStarting, StateRunning)
+		} else {
+			s.set(c, StateFailed, "failed to start")
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			inner.Wait()
+			s.advance(c, StateRunning, StateStopped)
+		}()
+		return ok
+	}
+}
+
+type failKey struct{}
+
+// Fail reports that the component started with ctx can no longer make progress, e.g. because its server or the
+// stream it consumes has stopped. The component is marked as failed if ctx was given to it by Status.Handler. Errors
+// reported once shutdown has begun are expected and ignored.
+func Fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	if fail, ok := ctx.Value(failKey{}).(func(error)); ok {
+		fail(err)
+	}
+}
+
+// Live reports whether every component is still able to make progress. Only a failed component makes the
+// application unhealthy, it is expected to be restarted at that point.
+func (s *Status) Live() Report {
+	r := Report{Healthy: true, Components: s.report()}
+	for _, c := range r.Components {
+		if c.State == StateFailed {
+			r.Healthy = false
+		}
+	}
+	return r
+}
+
+// Ready reports whether the application can do work, meaning every component is running and every dependency can be
+// reached. Checks are run concurrently, each bounded by a short timeout.
+func (s *Status) Ready(ctx context.Context) Report {
+	r := Report{Healthy: true, Components: s.report()}
+	for _, c := range r.Components {
+		if c.State != StateRunning {
+			r.Healthy = false
+		}
+	}
+
+	s.mutex.RLock()
+	checks := append([]namedCheck(nil), s.checks...)
+	s.mutex.RUnlock()
+
+	r.Checks = make([]CheckReport, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+			r.Checks[i].Name = checks[i].name
+			if err := checks[i].check(checkCtx); err != nil {
+				r.Checks[i].Error = err.Error()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range r.Checks {
+		if len(c.Error) > 0 {
+			r.Healthy = false
+		}
+	}
+	return r
+}
+
+func (s *Status) set(c *ComponentReport, state State, err string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c.State = state
+	c.Error = err
+}
+
+// advance moves c to the next state if it is still in the expected one, so that a failure is never overwritten
+func (s *Status) advance(c *ComponentReport, expected State, next State) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if c.State == expected {
+		c.State = next
+	}
+}
+
+func (s *Status) report() []ComponentReport {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	result := make([]ComponentReport, len(s.components))
+	for i, c := range s.components {
+		result[i] = *c
+	}
+	return result
+}
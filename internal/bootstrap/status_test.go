@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reachable := false
+	status := NewStatus()
+	status.AddCheck("db", func(ctx context.Context) error {
+		if !reachable {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+
+	chStop := make(chan struct{})
+	worker := status.Handler("worker", func(ctx context.Context, wg *sync.WaitGroup) bool {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-chStop:
+				Fail(ctx, errors.New("stream closed"))
+			case <-ctx.Done():
+			}
+		}()
+		return true
+	})
+
+	if r := status.Ready(ctx); r.Healthy || r.Components[0].State != StateStarting {
+		t.Fatalf("expected not ready before start, got %+v", r)
+	}
+
+	var wg sync.WaitGroup
+	if !worker(ctx, &wg) {
+		t.Fatalf("worker failed to start")
+	}
+	if r := status.Ready(ctx); r.Healthy || r.Checks[0].Error != "unreachable" {
+		t.Errorf("expected not ready while db is unreachable, got %+v", r)
+	}
+	reachable = true
+	if r := status.Ready(ctx); !r.Healthy {
+		t.Errorf("expected ready, got %+v", r)
+	}
+
+	// A component reporting a fatal error has failed
+	close(chStop)
+	wg.Wait()
+	if r := status.Live(); r.Healthy || r.Components[0].State != StateFailed || r.Components[0].Error != "stream closed" {
+		t.Errorf("expected worker to have failed, got %+v", r)
+	}
+}
+
+func TestStatusShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	status := NewStatus()
+	server := status.Handler("server", func(ctx context.Context, wg *sync.WaitGroup) bool {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ctx.Done()
+			// Errors while shutting down don't fail the component
+			Fail(ctx, errors.New("server closed"))
+		}()
+		return true
+	})
+
+	var wg sync.WaitGroup
+	if !server(ctx, &wg) {
+		t.Fatalf("server failed to start")
+	}
+	cancel()
+	wg.Wait()
+	if r := status.Live(); !r.Healthy || r.Components[0].State != StateStopped {
+		t.Errorf("expected server to have stopped, got %+v", r)
+	}
+}
@@ -16,39 +16,67 @@ package calculator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
 	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/alerting"
 	"github.com/project-alvarium/scoring-apps-go/internal/calculator/types"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
 	"github.com/project-alvarium/scoring-apps-go/pkg/policies"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"sync"
 	"time"
 )
 
 type Calculator struct {
+	chAlerts  chan alerting.Evaluation // chAlerts receives every persisted score when alerting is enabled, otherwise nil
 	chKeys    chan string
 	condition *sync.Cond
-	dbClient  *ArangoClient
+	dbClient  scoreStore
 	dbConfig  config.DatabaseInfo
 	logger    logInterface.Logger
+	pending   *types.PendingAcks
+	traces    *types.KeyTraces
 	workQueue *types.WorkQueue
-	policy    policies.DcfPolicy
+	policies  []policies.DcfPolicy // policies are each applied to the same annotations, yielding one score per classifier
 }
 
 const (
 	workerMax int = 5
 )
 
-func NewCalculator(chKeys chan string, dbConfig config.DatabaseInfo, logger logInterface.Logger, policy policies.DcfPolicy) Calculator {
+// scoreStore is the part of the ArangoClient that scoring relies on
+type scoreStore interface {
+	QueryAnnotations(ctx context.Context, key string) ([]documents.Annotation, error)
+	CreateDocument(ctx context.Context, documentKey string, document interface{}, collectionName string) error
+	CreateEdge(ctx context.Context, src string, target string, collectionName string) error
+}
+
+// NoAnnotationsError is returned by Score for a key that has no annotations, which can't be given a confidence.
+type NoAnnotationsError struct {
+	Key string
+}
+
+func (e NoAnnotationsError) Error() string {
+	return fmt.Sprintf("no annotations found for key %s", e.Key)
+}
+
+func NewCalculator(chKeys chan string, chAlerts chan alerting.Evaluation, dbConfig config.DatabaseInfo, pending *types.PendingAcks,
+	traces *types.KeyTraces, logger logInterface.Logger, dcfPolicies []policies.DcfPolicy) Calculator {
 	return Calculator{
+		chAlerts:  chAlerts,
 		chKeys:    chKeys,
 		condition: sync.NewCond(&sync.Mutex{}),
 		dbConfig:  dbConfig,
 		logger:    logger,
+		pending:   pending,
+		traces:    traces,
 		workQueue: types.NewWorkQueue(),
-		policy:    policy,
+		policies:  dcfPolicies,
 	}
 }
 
@@ -85,6 +113,8 @@ func (c *Calculator) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) b
 	go func() {
 		defer wg.Done()
 		for !cancelled {
+			workQueueDepth.Set(float64(c.workQueue.Len()))
+			activeWorkers.Set(float64(c.workQueue.Workers.Count()))
 			if c.workQueue.Len() > 0 {
 				c.condition.L.Lock()
 				if c.workQueue.Workers.Count() >= workerMax {
@@ -118,23 +148,90 @@ func (c *Calculator) score(ctx context.Context, key string) {
 	defer c.workQueue.Workers.Decrement()
 
 	time.Sleep(1500 * time.Millisecond)
-	annotations, err := c.dbClient.QueryAnnotations(ctx, key)
-	if err != nil {
-		c.logger.Error(err.Error())
+	// Only messages received before scoring starts are satisfied by this score
+	acks := c.pending.Take(key)
+	ctx, span := c.startScore(ctx, key)
+	start := time.Now()
+	_, err := c.Score(ctx, key)
+	scoringDuration.Observe(time.Since(start).Seconds())
+	tracing.End(span, err)
+	c.settle(key, acks, err)
+	c.condition.Signal()
+}
+
+// settle acknowledges the messages satisfied by scoring the key. A key without annotations will never be scored, so
+// its messages are acknowledged as well rather than left to be redelivered or to hold back their partition. After any
+// other error, e.g. the database being unreachable, the messages are returned to the pending acknowledgements and the
+// key is queued to be scored again.
+func (c *Calculator) settle(key string, acks []func() error, err error) {
+	if err == nil {
+		acknowledge(acks, c.logger)
 		return
 	}
-
-	docScore := documents.NewScore(key, annotations, c.policy)
-	err = c.dbClient.CreateDocument(ctx, docScore.Key.String(), docScore, documents.VertexScores)
-	if err != nil {
-		c.logger.Error(err.Error())
+	c.logger.Error(err.Error())
+	var noAnnotations NoAnnotationsError
+	if errors.As(err, &noAnnotations) {
+		acknowledge(acks, c.logger)
 		return
 	}
-	err = c.dbClient.CreateEdge(ctx, docScore.Key.String(), key, documents.EdgeScoring)
+	for _, ack := range acks {
+		c.pending.Add(key, ack)
+	}
+	c.workQueue.Append(key)
+}
+
+// startScore begins the score span as part of the trace of the first message that requested the key, linked to the
+// traces of any others.
+func (c *Calculator) startScore(ctx context.Context, key string) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{trace.WithAttributes(attribute.String("alvarium.key", key))}
+	collected := c.traces.Take(key)
+	if len(collected) > 0 {
+		ctx = trace.ContextWithSpanContext(ctx, collected[0])
+		for _, sc := range collected[1:] {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+		}
+	}
+	return tracing.Start(ctx, tracing.SpanScore, opts...)
+}
+
+// Score calculates and persists a score per policy for the given key, returning the scores written.
+func (c *Calculator) Score(ctx context.Context, key string) ([]documents.Score, error) {
+	annotations, err := c.dbClient.QueryAnnotations(ctx, key)
 	if err != nil {
-		c.logger.Error(err.Error())
-		return
+		return nil, err
+	}
+	if len(annotations) == 0 {
+		return nil, NoAnnotationsError{Key: key}
 	}
 
-	c.condition.Signal()
+	var scores []documents.Score
+	for _, p := range c.policies {
+		docScore := documents.NewScore(key, annotations, p)
+		docScore.Trace = tracing.Inject(ctx)
+		err = c.dbClient.CreateDocument(ctx, docScore.Key, docScore, documents.VertexScores)
+		if err != nil {
+			return scores, err
+		}
+		err = c.dbClient.CreateEdge(ctx, docScore.Key, key, documents.EdgeScoring)
+		if err != nil {
+			return scores, err
+		}
+		scores = append(scores, docScore)
+		confidence.WithLabelValues(docScore.Policy).Observe(docScore.Confidence)
+
+		if c.chAlerts != nil {
+			select {
+			case c.chAlerts <- alerting.Evaluation{Score: docScore, Annotations: annotations}:
+			case <-ctx.Done():
+			}
+		}
+	}
+	return scores, nil
+}
+
+// Enqueue adds keys directly to the work queue, bypassing the Collector's wait for further annotations.
+func (c *Calculator) Enqueue(keys ...string) {
+	for _, k := range keys {
+		c.workQueue.Append(k)
+	}
 }
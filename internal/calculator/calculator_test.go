@@ -0,0 +1,194 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package calculator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/calculator/types"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"github.com/project-alvarium/scoring-apps-go/pkg/policies"
+	"github.com/project-alvarium/scoring-apps-go/pkg/responses"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testWeights = []policies.Weight{{AnnotationKey: "tpm", Value: 3}, {AnnotationKey: "tls", Value: 1}}
+
+var testAnnotations = []documents.Annotation{
+	{Key: "a1", DataRef: "k1", Kind: "tpm", IsSatisfied: true},
+	{Key: "a2", DataRef: "k1", Kind: "tls", IsSatisfied: false},
+}
+
+func TestCalculatorScore(t *testing.T) {
+	store := newTestStore()
+	calc := newTestCalculator(store)
+
+	scores, err := calc.Score(context.Background(), "k1")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	confidence := make(map[string]float64)
+	for _, s := range scores {
+		confidence[s.Policy] = s.Confidence
+	}
+	if len(scores) != 2 || confidence["default"] != 0.5 || confidence["weighted"] != 0.75 {
+		t.Errorf("unexpected scores %v", confidence)
+	}
+	if len(store.documents) != 2 || len(store.edges) != 2 {
+		t.Errorf("expected a score and an edge per policy, got %v scores and %v edges", len(store.documents), len(store.edges))
+	}
+
+	_, err = calc.Score(context.Background(), "unknown")
+	var notFound NoAnnotationsError
+	if !errors.As(err, &notFound) || len(store.documents) != 2 {
+		t.Errorf("expected a key without annotations to be rejected unscored, got %v", err)
+	}
+}
+
+func TestCalculatorEnqueue(t *testing.T) {
+	calc := newTestCalculator(newTestStore())
+	calc.Enqueue("k1", "k2")
+	if calc.workQueue.Len() != 2 || calc.workQueue.First() != "k1" {
+		t.Errorf("expected both keys queued in order")
+	}
+}
+
+func TestCalculatorSettle(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectAcked  bool
+		expectQueued bool
+	}{
+		{"scored", nil, true, false},
+		{"no annotations", NoAnnotationsError{Key: "k1"}, true, false},
+		{"database unreachable", errors.New("connection refused"), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := newTestCalculator(newTestStore())
+			acked := false
+			calc.settle("k1", []func() error{func() error {
+				acked = true
+				return nil
+			}}, tt.err)
+
+			if acked != tt.expectAcked {
+				t.Errorf("expected acknowledged %v, got %v", tt.expectAcked, acked)
+			}
+			if queued := calc.workQueue.Len() == 1; queued != tt.expectQueued {
+				t.Errorf("expected queued %v, got %v", tt.expectQueued, queued)
+			}
+			// Messages that weren't acknowledged are kept for the next attempt at scoring the key
+			if pending := len(calc.pending.Take("k1")) == 1; pending == tt.expectAcked {
+				t.Errorf("expected the acknowledgement to be pending only when it wasn't made")
+			}
+		})
+	}
+}
+
+func TestRestRoutes(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	r := mux.NewRouter()
+	LoadRestRoutes(r, newTestCalculator(newTestStore()), testProvider{}, logger)
+
+	tests := []struct {
+		name         string
+		path         string
+		body         interface{}
+		expectStatus int
+		expectCount  int
+	}{
+		{"score key", "/score/k1", nil, http.StatusOK, 2},
+		{"score key without annotations", "/score/unknown", nil, http.StatusNotFound, 0},
+		{"enqueue keys", "/enqueue", map[string]interface{}{"keys": []string{"k1"}}, http.StatusAccepted, 0},
+		{"enqueue no keys", "/enqueue", map[string]interface{}{}, http.StatusBadRequest, 0},
+		{"what if", "/whatif", map[string]interface{}{"classifier": "default", "annotations": testAnnotations}, http.StatusOK, 1},
+		{"what if no annotations", "/whatif", map[string]interface{}{"classifier": "default"}, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(b)))
+			if w.Code != tt.expectStatus {
+				t.Fatalf("expected status %v, received %v %s", tt.expectStatus, w.Code, w.Body.String())
+			}
+			if tt.expectCount == 0 {
+				return
+			}
+			var response responses.ScoreListResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			if err != nil || response.Count != tt.expectCount {
+				t.Errorf("expected %v scores, received %s", tt.expectCount, w.Body.String())
+			}
+		})
+	}
+}
+
+func newTestCalculator(store scoreStore) *Calculator {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	dcfPolicies := []policies.DcfPolicy{
+		{Name: "default", Weights: []policies.Weight{{AnnotationKey: "tpm", Value: 1}, {AnnotationKey: "tls", Value: 1}}},
+		{Name: "weighted", Weights: testWeights},
+	}
+	calc := NewCalculator(make(chan string), nil, config.DatabaseInfo{}, types.NewPendingAcks(), types.NewKeyTraces(),
+		logger, dcfPolicies)
+	calc.dbClient = store
+	return &calc
+}
+
+// testStore keeps the documents written by the calculator in memory
+type testStore struct {
+	annotations map[string][]documents.Annotation
+	documents   map[string]interface{}
+	edges       []string
+}
+
+func newTestStore() *testStore {
+	return &testStore{
+		annotations: map[string][]documents.Annotation{"k1": testAnnotations},
+		documents:   make(map[string]interface{}),
+	}
+}
+
+func (s *testStore) QueryAnnotations(ctx context.Context, key string) ([]documents.Annotation, error) {
+	return s.annotations[key], nil
+}
+
+func (s *testStore) CreateDocument(ctx context.Context, documentKey string, document interface{}, collectionName string) error {
+	s.documents[documentKey] = document
+	return nil
+}
+
+func (s *testStore) CreateEdge(ctx context.Context, src string, target string, collectionName string) error {
+	s.edges = append(s.edges, src+"/"+target)
+	return nil
+}
+
+type testProvider struct{}
+
+func (p testProvider) GetWeights(classifier string) ([]policies.Weight, error) {
+	return testWeights, nil
+}
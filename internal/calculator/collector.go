@@ -31,19 +31,33 @@ const (
 // Collector is responsible for maintaining a map of all of the dequeued keys. It collects these keys in order to
 // de-duplicate them so we don't calculate the score for the same key more than once (hopefully) or otherwise when
 // the annotations are incomplete.
+//
+// When sharding is enabled every replica collects every key, but only hands off the keys it owns once they are ready.
+// A replica that crashes stays on the ring until its heartbeat expires, so a key that isn't owned is held for
+// Membership.Settle and scored if ownership passes to this replica in that time. Keys are only lost if their owner
+// stops after the hold has ended but before scoring them.
 type Collector struct {
-	chPub  chan string
-	chSub  chan string
-	logger logInterface.Logger
-	keyMap *types.KeyMap
+	chPub      chan string
+	chSub      chan string
+	deferred   map[string]time.Time // deferred holds keys owned by another replica, until the time they are dropped
+	logger     logInterface.Logger
+	keyMap     *types.KeyMap
+	membership *Membership // membership is nil unless sharding is enabled
+	pending    *types.PendingAcks
+	traces     *types.KeyTraces
 }
 
-func NewCollector(chKeys chan string, chPub chan string, logger logInterface.Logger) Collector {
+func NewCollector(chKeys chan string, chPub chan string, membership *Membership, pending *types.PendingAcks, traces *types.KeyTraces,
+	logger logInterface.Logger) Collector {
 	return Collector{
-		chPub:  chPub,
-		chSub:  chKeys,
-		logger: logger,
-		keyMap: types.NewKeyMap(),
+		chPub:      chPub,
+		chSub:      chKeys,
+		deferred:   make(map[string]time.Time),
+		logger:     logger,
+		keyMap:     types.NewKeyMap(),
+		membership: membership,
+		pending:    pending,
+		traces:     traces,
 	}
 }
 
@@ -71,9 +85,19 @@ func (c *Collector) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bo
 			if !cancelled {
 				time.Sleep(time.Millisecond * time.Duration(tickInterval))
 				keys := c.keyMap.Poll(pollingInterval)
+				keyMapKeys.Set(float64(c.keyMap.Len()))
 				for _, k := range keys {
+					c.traces.Collected(k)
+					if c.membership != nil && !c.membership.Owns(k) {
+						if _, ok := c.deferred[k]; !ok {
+							c.deferred[k] = time.Now().Add(c.membership.Settle())
+						}
+						continue
+					}
+					delete(c.deferred, k)
 					c.chPub <- k
 				}
+				c.handOffDeferred(time.Now())
 			} else {
 				close(c.chPub)
 				return
@@ -91,3 +115,21 @@ func (c *Collector) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bo
 	}()
 	return true
 }
+
+// handOffDeferred scores the deferred keys this replica has since come to own. Keys still owned by another replica
+// once their hold has ended are left to their owner, which scores them from its own delivery, so this replica's
+// messages are done with.
+func (c *Collector) handOffDeferred(now time.Time) {
+	for k, until := range c.deferred {
+		if c.membership.Owns(k) {
+			delete(c.deferred, k)
+			c.chPub <- k
+			continue
+		}
+		if now.After(until) {
+			delete(c.deferred, k)
+			acknowledge(c.pending.Take(k), c.logger)
+			c.traces.Take(k)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package calculator
+
+import (
+	"fmt"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/calculator/types"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"testing"
+	"time"
+)
+
+func TestCollectorOwnership(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	m := &Membership{
+		cfg:  config.ShardingInfo{InstanceId: "calculator-a", Heartbeat: 10, Expiry: 30},
+		ring: types.NewHashRing(defaultRingReplicas),
+	}
+	m.ring.Set([]string{"calculator-a", "calculator-b"})
+	var key string
+	for i := 0; len(key) == 0; i++ {
+		if k := fmt.Sprintf("key-%v", i); !m.Owns(k) {
+			key = k
+		}
+	}
+
+	acked := 0
+	pending := types.NewPendingAcks()
+	pending.Add(key, func() error {
+		acked++
+		return nil
+	})
+	chPub := make(chan string, 1)
+	c := NewCollector(nil, chPub, m, pending, types.NewKeyTraces(), logger)
+	now := time.Now()
+	c.deferred[key] = now.Add(m.Settle())
+
+	c.handOffDeferred(now)
+	if len(chPub) != 0 || acked != 0 {
+		t.Fatalf("expected a key owned by a live replica to be held")
+	}
+
+	// The owner drops out of the ring before the hold ends, so this replica now scores the key
+	m.ring.Set([]string{"calculator-a"})
+	c.handOffDeferred(now)
+	if len(chPub) != 1 || <-chPub != key || acked != 0 {
+		t.Fatalf("expected the key to be handed off for scoring once owned")
+	}
+
+	// A key still owned elsewhere once the hold has ended is left to its owner
+	m.ring.Set([]string{"calculator-a", "calculator-b"})
+	c.deferred[key] = now
+	c.handOffDeferred(now.Add(time.Millisecond))
+	if len(chPub) != 0 || acked != 1 || len(c.deferred) != 0 {
+		t.Errorf("expected the key to be acknowledged and dropped, acked %v times", acked)
+	}
+}
@@ -16,15 +16,23 @@ package calculator
 
 import (
 	"encoding/json"
+	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
 	logging "github.com/project-alvarium/provider-logging/pkg/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
 )
 
 type ApplicationConfig struct {
-	Database config.DatabaseInfo `json:"database,omitempty"`
-	Stream   config.PubSubInfo   `json:"stream,omitempty"`
-	Logging  logging.LoggingInfo `json:"logging,omitempty"`
-	Policy   config.PolicyInfo   `json:"policy,omitempty"`
+	Alerting    config.AlertingInfo   `json:"alerting,omitempty"`
+	Classifiers []string              `json:"classifiers,omitempty"` // Classifiers lists the policies to score each key against. Overrides the -mode flag.
+	Database    config.DatabaseInfo   `json:"database,omitempty"`
+	Endpoint    SdkConfig.ServiceInfo `json:"endpoint,omitempty"` // Endpoint enables the on-demand scoring API when a port is set
+	Stream      config.PubSubInfo     `json:"stream,omitempty"`
+	Logging     logging.LoggingInfo   `json:"logging,omitempty"`
+	Metrics     config.MetricsInfo    `json:"metrics,omitempty"`
+	Health      config.HealthInfo     `json:"health,omitempty"`
+	Policy      config.PolicyInfo     `json:"policy,omitempty"`
+	Sharding    config.ShardingInfo   `json:"sharding,omitempty"`
+	Tracing     config.TracingInfo    `json:"tracing,omitempty"`
 }
 
 func (a ApplicationConfig) AsString() string {
@@ -59,6 +59,12 @@ func NewArangoClient(dbConfig config.DatabaseInfo, logger logInterface.Logger) (
 	return &c, nil
 }
 
+// Check reports whether the Arango server can be reached
+func (c *ArangoClient) Check(ctx context.Context) error {
+	_, err := c.client.Version(ctx)
+	return err
+}
+
 func (c *ArangoClient) CreateDocument(ctx context.Context, documentKey string, document interface{}, collectionName string) error {
 	db, err := c.client.Database(ctx, c.cfg.DatabaseName)
 	if err != nil {
@@ -75,17 +81,12 @@ func (c *ArangoClient) CreateDocument(ctx context.Context, documentKey string, d
 		return err
 	}
 
-	exists, err := coll.DocumentExists(ctx, documentKey)
+	// Documents with deterministic keys are upserted, so writing the same document twice leaves a single copy
+	_, err = coll.CreateDocument(driver.WithOverwriteMode(ctx, driver.OverwriteModeReplace), document)
 	if err != nil {
 		return err
 	}
-
-	if !exists {
-		_, err := coll.CreateDocument(ctx, document)
-		if err != nil {
-			return err
-		}
-	}
+	c.logger.Write(logging.TraceLevel, "document upserted "+collectionName+"/"+documentKey)
 	return nil
 }
 
@@ -108,10 +109,11 @@ func (c *ArangoClient) CreateEdge(ctx context.Context, src string, target string
 	switch collectionName {
 	case documents.EdgeScoring:
 		doc := documents.Scoring{
+			Key:  src,
 			From: fmt.Sprintf("%s/%s", documents.VertexScores, src),
 			To:   fmt.Sprintf("%s/%s", documents.VertexData, target),
 		}
-		_, err = edge.CreateDocument(ctx, doc)
+		_, err = edge.CreateDocument(driver.WithOverwriteMode(ctx, driver.OverwriteModeIgnore), doc)
 	}
 	return err
 }
@@ -145,6 +147,96 @@ func (c *ArangoClient) QueryAnnotations(ctx context.Context, key string) ([]docu
 	return annotations, nil
 }
 
+// EnsureCollection creates the named document collection if it does not already exist. Unlike the graph collections,
+// collections used for coordination are not created by the subscriber.
+func (c *ArangoClient) EnsureCollection(ctx context.Context, collectionName string) error {
+	db, err := c.client.Database(ctx, c.cfg.DatabaseName)
+	if err != nil {
+		return err
+	}
+	exists, err := db.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		c.logger.Write(logging.DebugLevel, "creating collection "+collectionName)
+		_, err = db.CreateCollection(ctx, collectionName, nil)
+		if err != nil && !driver.IsConflict(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Heartbeat writes the member document, replacing any previous heartbeat for the same replica. The time is taken from
+// the Arango server so that heartbeats from different hosts are comparable regardless of their clocks.
+func (c *ArangoClient) Heartbeat(ctx context.Context, key string, collectionName string) error {
+	db, err := c.client.Database(ctx, c.cfg.DatabaseName)
+	if err != nil {
+		return err
+	}
+	query := "UPSERT { _key: @key } INSERT { _key: @key, timestamp: DATE_ISO8601(DATE_NOW()) } " +
+		"UPDATE { timestamp: DATE_ISO8601(DATE_NOW()) } IN @@collection"
+	bindVars := map[string]interface{}{
+		"@collection": collectionName,
+		"key":         key,
+	}
+	cursor, err := db.Query(ctx, query, bindVars)
+	if err != nil {
+		return err
+	}
+	return cursor.Close()
+}
+
+// RemoveMember deletes the member document so that other replicas rebalance without waiting for it to expire.
+func (c *ArangoClient) RemoveMember(ctx context.Context, key string, collectionName string) error {
+	db, err := c.client.Database(ctx, c.cfg.DatabaseName)
+	if err != nil {
+		return err
+	}
+	coll, err := db.Collection(ctx, collectionName)
+	if err != nil {
+		return err
+	}
+	_, err = coll.RemoveDocument(ctx, key)
+	if driver.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// QueryMembers returns the members whose last heartbeat, by the Arango server's clock, is younger than expiry
+// milliseconds.
+func (c *ArangoClient) QueryMembers(ctx context.Context, expiry int64, collectionName string) ([]documents.Member, error) {
+	db, err := c.client.Database(ctx, c.cfg.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	query := "FOR m in @@collection FILTER DATE_TIMESTAMP(m.timestamp) > DATE_NOW() - @expiry RETURN m"
+	bindVars := map[string]interface{}{
+		"@collection": collectionName,
+		"expiry":      expiry,
+	}
+	cursor, err := db.Query(ctx, query, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	var members []documents.Member
+	for {
+		var doc documents.Member
+		_, err := cursor.ReadDocument(ctx, &doc)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		members = append(members, doc)
+	}
+	return members, nil
+}
+
 func (c *ArangoClient) ValidateGraph(ctx context.Context) error {
 	exists, err := c.client.DatabaseExists(ctx, c.cfg.DatabaseName)
 	if err != nil {
@@ -0,0 +1,179 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package calculator
+
+import (
+	"context"
+	"fmt"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/calculator/types"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMembersCollection string = "calculators"
+	defaultHeartbeat         int64  = 2000
+	defaultRingReplicas      int    = 64
+)
+
+// Membership tracks the calculator replicas that are alive and decides which of them owns a given key. Every replica
+// receives every key, so ownership is what guarantees each key is scored by exactly one of them.
+type Membership struct {
+	cfg      config.ShardingInfo
+	dbClient *ArangoClient
+	dbConfig config.DatabaseInfo
+	logger   logInterface.Logger
+	ring     *types.HashRing
+}
+
+func NewMembership(cfg config.ShardingInfo, dbConfig config.DatabaseInfo, logger logInterface.Logger) (*Membership, error) {
+	if len(cfg.InstanceId) == 0 {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		cfg.InstanceId = host
+	}
+	if len(cfg.Collection) == 0 {
+		cfg.Collection = defaultMembersCollection
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = defaultHeartbeat
+	}
+	if cfg.Expiry <= cfg.Heartbeat {
+		cfg.Expiry = cfg.Heartbeat * 3
+	}
+	if cfg.Replicas <= 0 {
+		cfg.Replicas = defaultRingReplicas
+	}
+
+	return &Membership{
+		cfg:      cfg,
+		dbConfig: dbConfig,
+		logger:   logger,
+		ring:     types.NewHashRing(cfg.Replicas),
+	}, nil
+}
+
+// CheckStream rejects a CalculateScore subscription whose messages are shared between replicas. Sharding relies on every
+// replica receiving every key, so with a NATS durable or Kafka group shared between replicas a key delivered to a
+// replica that doesn't own it would never reach its owner. A durable or group is taken to be per replica when its name
+// contains the replica's instance id.
+func (m *Membership) CheckStream(stream config.StreamInfo) error {
+	var kind, name string
+	switch t := stream.Config.(type) {
+	case config.NatsConfig:
+		kind, name = "durable", t.Durable
+	case config.KafkaConfig:
+		kind, name = "groupId", t.GroupId
+	default:
+		return nil
+	}
+	if !strings.Contains(name, m.cfg.InstanceId) {
+		return fmt.Errorf("sharding requires a %s stream %s unique to each replica, %s does not contain instanceId %s",
+			stream.Type, kind, name, m.cfg.InstanceId)
+	}
+	return nil
+}
+
+// Owns reports whether this replica is responsible for scoring the key. If membership is not yet known the replica
+// assumes ownership, preferring a duplicate score over a missing one.
+func (m *Membership) Owns(key string) bool {
+	owner := m.ring.Owner(key)
+	return len(owner) == 0 || owner == m.cfg.InstanceId
+}
+
+// Settle is the longest a replica that has stopped can remain on the ring of the others, its heartbeat expiry plus
+// the time taken for them to notice.
+func (m *Membership) Settle() time.Duration {
+	return time.Millisecond * time.Duration(m.cfg.Expiry+m.cfg.Heartbeat)
+}
+
+func (m *Membership) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool {
+	db, err := NewArangoClient(m.dbConfig, m.logger)
+	if err != nil {
+		m.logger.Error(err.Error())
+		return false
+	}
+	err = db.EnsureCollection(ctx, m.cfg.Collection)
+	if err != nil {
+		m.logger.Error(err.Error())
+		return false
+	}
+	m.dbClient = db
+
+	// Join the ring before any keys are polled so this replica doesn't briefly claim the whole key space
+	err = m.refresh(ctx)
+	if err != nil {
+		m.logger.Error(err.Error())
+		return false
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(time.Millisecond * time.Duration(m.cfg.Heartbeat))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := m.refresh(ctx)
+				if err != nil {
+					m.logger.Error(err.Error())
+				}
+			case <-ctx.Done():
+				// ctx is already cancelled so leaving the ring needs a context of its own
+				leaveCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(m.cfg.Heartbeat))
+				err := m.dbClient.RemoveMember(leaveCtx, m.cfg.InstanceId, m.cfg.Collection)
+				cancel()
+				if err != nil {
+					m.logger.Error(err.Error())
+				}
+				m.logger.Write(logging.InfoLevel, "shutdown received")
+				return
+			}
+		}
+	}()
+	return true
+}
+
+// refresh records this replica's heartbeat and rebuilds the ring from the replicas that are still alive.
+func (m *Membership) refresh(ctx context.Context) error {
+	err := m.dbClient.Heartbeat(ctx, m.cfg.InstanceId, m.cfg.Collection)
+	if err != nil {
+		return err
+	}
+
+	members, err := m.dbClient.QueryMembers(ctx, m.cfg.Expiry, m.cfg.Collection)
+	if err != nil {
+		return err
+	}
+	ids := []string{m.cfg.InstanceId}
+	for _, member := range members {
+		if member.Key != m.cfg.InstanceId {
+			ids = append(ids, member.Key)
+		}
+	}
+	if m.ring.Set(ids) {
+		m.logger.Write(logging.InfoLevel, fmt.Sprintf("calculator membership changed %v", m.ring.Members()))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package calculator
+
+import (
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"testing"
+)
+
+func TestMembershipCheckStream(t *testing.T) {
+	m := &Membership{cfg: config.ShardingInfo{InstanceId: "calculator-1"}}
+
+	tests := []struct {
+		name        string
+		stream      config.StreamInfo
+		expectError bool
+	}{
+		{"nats shared durable", config.StreamInfo{Type: config.NatsStream, Config: config.NatsConfig{Durable: "calculator-go"}}, true},
+		{"nats replica durable", config.StreamInfo{Type: config.NatsStream, Config: config.NatsConfig{Durable: "calculator-1"}}, false},
+		{"kafka shared group", config.StreamInfo{Type: config.KafkaStream, Config: config.KafkaConfig{GroupId: "calculators"}}, true},
+		{"kafka replica group", config.StreamInfo{Type: config.KafkaStream, Config: config.KafkaConfig{GroupId: "scores-calculator-1"}}, false},
+		{"memory", config.StreamInfo{Type: config.MemoryStream}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.CheckStream(tt.stream)
+			if tt.expectError && err == nil {
+				t.Errorf("expected an error for %s", tt.name)
+			} else if !tt.expectError && err != nil {
+				t.Errorf("unexpected error %s", err.Error())
+			}
+		})
+	}
+}
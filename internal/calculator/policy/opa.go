@@ -16,7 +16,9 @@ package policy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/pkg/policies"
 	"github.com/project-alvarium/scoring-apps-go/pkg/requests"
@@ -25,6 +27,9 @@ import (
 	"net/http"
 )
 
+// opaHealthPath is OPA's own health API, which responds once the server is ready to evaluate policies
+const opaHealthPath = "/health"
+
 type OpenPolicyProvider struct {
 	cfg config.OpenPolicyConfig
 }
@@ -35,6 +40,23 @@ func NewOpenPolicyProvider(cfg config.OpenPolicyConfig) PolicyProvider {
 	return &p
 }
 
+// Check reports whether the OPA server is reachable and healthy
+func (p *OpenPolicyProvider) Check(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Provider.Uri()+opaHealthPath, nil)
+	if err != nil {
+		return err
+	}
+	result, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	result.Body.Close()
+	if result.StatusCode != http.StatusOK {
+		return fmt.Errorf("OPA health returned %s", result.Status)
+	}
+	return nil
+}
+
 func (p *OpenPolicyProvider) GetWeights(classifier string) ([]policies.Weight, error) {
 
 	// Send request
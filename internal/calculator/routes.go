@@ -0,0 +1,163 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package calculator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/calculator/policy"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"github.com/project-alvarium/scoring-apps-go/pkg/policies"
+	"github.com/project-alvarium/scoring-apps-go/pkg/requests"
+	"github.com/project-alvarium/scoring-apps-go/pkg/responses"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	headerKeyContentType string = "Content-Type"
+	headerValueJson      string = "application/json"
+)
+
+// LoadRestRoutes registers the on-demand scoring operations. Scoring a key now and enqueueing keys use the policies
+// the calculator was started with, while what-if fetches the weights of any classifier from the PolicyProvider.
+func LoadRestRoutes(r *mux.Router, calc *Calculator, provider policy.PolicyProvider, logger interfaces.Logger) {
+	r.HandleFunc("/score/{key}",
+		func(w http.ResponseWriter, r *http.Request) {
+			postScoreHandler(w, r, calc, logger)
+		}).Methods(http.MethodPost)
+
+	r.HandleFunc("/enqueue",
+		func(w http.ResponseWriter, r *http.Request) {
+			postEnqueueHandler(w, r, calc, logger)
+		}).Methods(http.MethodPost)
+
+	r.HandleFunc("/whatif",
+		func(w http.ResponseWriter, r *http.Request) {
+			postWhatIfHandler(w, r, provider, logger)
+		}).Methods(http.MethodPost)
+}
+
+func postScoreHandler(w http.ResponseWriter, r *http.Request, calc *Calculator, logger interfaces.Logger) {
+	defer r.Body.Close()
+
+	key := mux.Vars(r)["key"]
+	scores, err := calc.Score(r.Context(), key)
+	if err != nil {
+		var notFound NoAnnotationsError
+		if errors.As(err, &notFound) {
+			logger.Write(logging.DebugLevel, err.Error())
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		logger.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	logger.Write(logging.DebugLevel, fmt.Sprintf("scored on demand %s", key))
+	writeScores(w, scores, logger)
+}
+
+func postEnqueueHandler(w http.ResponseWriter, r *http.Request, calc *Calculator, logger interfaces.Logger) {
+	defer r.Body.Close()
+
+	var request requests.EnqueueRequest
+	if !readRequest(w, r, &request, logger) {
+		return
+	}
+	if len(request.Keys) == 0 {
+		errMsg := "Bad request: no keys provided"
+		logger.Write(logging.DebugLevel, errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errMsg))
+		return
+	}
+
+	calc.Enqueue(request.Keys...)
+	logger.Write(logging.DebugLevel, fmt.Sprintf("enqueued %v keys on demand", len(request.Keys)))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func postWhatIfHandler(w http.ResponseWriter, r *http.Request, provider policy.PolicyProvider, logger interfaces.Logger) {
+	defer r.Body.Close()
+
+	var request requests.WhatIfRequest
+	if !readRequest(w, r, &request, logger) {
+		return
+	}
+	if len(request.Classifier) == 0 {
+		errMsg := "Bad request: no classifier provided"
+		logger.Write(logging.DebugLevel, errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errMsg))
+		return
+	}
+	if len(request.Annotations) == 0 {
+		errMsg := "Bad request: no annotations provided"
+		logger.Write(logging.DebugLevel, errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errMsg))
+		return
+	}
+
+	weights, err := provider.GetWeights(request.Classifier)
+	if err != nil {
+		logger.Error(err.Error())
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	p := policies.DcfPolicy{Name: request.Classifier, Weights: weights}
+	writeScores(w, []documents.Score{documents.NewScore(request.DataRef, request.Annotations, p)}, logger)
+}
+
+// readRequest unmarshals the request body into target, writing a bad request response if that isn't possible.
+func readRequest(w http.ResponseWriter, r *http.Request, target interface{}, logger interfaces.Logger) bool {
+	b, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(b, target)
+	}
+	if err != nil {
+		logger.Write(logging.DebugLevel, "Bad request: "+err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return false
+	}
+	return true
+}
+
+func writeScores(w http.ResponseWriter, scores []documents.Score, logger interfaces.Logger) {
+	response := responses.ScoreListResponse{
+		Count:  len(scores),
+		Scores: scores,
+	}
+	b, err := json.Marshal(response)
+	if err != nil {
+		logger.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Add(headerKeyContentType, headerValueJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -16,22 +16,38 @@ package calculator
 
 import (
 	"context"
-	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"errors"
+	"fmt"
 	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
 	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
+	"github.com/project-alvarium/scoring-apps-go/internal/calculator/types"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/factories"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"sync"
 )
 
+// supportedVersions are the major schema versions of the CalculateScore messages the calculator accepts
+var supportedVersions = []int{0, 1}
+
 type Subscriber struct {
 	chKeys   chan string
 	instance interfaces.Subscriber
 	logger   logInterface.Logger
+	pending  *types.PendingAcks
+	traces   *types.KeyTraces
 }
 
-func NewSubscriber(endpoint SdkConfig.StreamInfo, chKeys chan string, logger logInterface.Logger) (Subscriber, error) {
+// NewSubscriber creates the CalculateScore subscriber. Messages from providers that expect acknowledgement are
+// recorded in pending, to be acknowledged only once their key has been scored. Each message's trace is continued by a
+// collect span recorded in traces.
+func NewSubscriber(endpoint config.StreamInfo, chKeys chan string, pending *types.PendingAcks, traces *types.KeyTraces,
+	logger logInterface.Logger) (Subscriber, error) {
 	t, err := factories.NewSubscriber(endpoint)
 	if err != nil {
 		return Subscriber{}, err
@@ -40,6 +56,8 @@ func NewSubscriber(endpoint SdkConfig.StreamInfo, chKeys chan string, logger log
 		chKeys:   chKeys,
 		instance: t,
 		logger:   logger,
+		pending:  pending,
+		traces:   traces,
 	}, nil
 }
 
@@ -48,20 +66,46 @@ func (s *Subscriber) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) b
 	chErrors := make(chan error)
 	go logErrors(chErrors, s.logger)
 
-	chMessages := make(chan msg.SubscribeWrapper)
-	go s.instance.Subscribe(ctx, chMessages, chErrors)
+	chMessages := make(chan msg.Delivery)
+	err := s.instance.Subscribe(ctx, chMessages, chErrors)
+	if err != nil {
+		s.logger.Error(err.Error())
+		close(chErrors)
+		return false
+	}
 
 	wg.Add(1)
 	go func() { // Process messages
 		defer wg.Done()
 
 		for {
-			msg, ok := <-chMessages
+			d, ok := <-chMessages
 			if !ok {
+				// The provider only stops delivering before shutdown if it can't continue
+				bootstrap.Fail(ctx, errors.New("deliveries stopped before shutdown"))
 				return
 			}
 			if !cancelled {
-				s.chKeys <- string(msg.Content)
+				wrap, err := openKey(d.Payload)
+				if err != nil {
+					// A payload that can't be read will never succeed, don't let it be redelivered
+					if len(wrap.MessageId) > 0 {
+						err = fmt.Errorf("rejected message %s from %s: %s", wrap.MessageId, wrap.Producer, err.Error())
+					}
+					s.logger.Error(err.Error())
+					if d.Ack != nil {
+						acknowledge([]func() error{d.Ack}, s.logger)
+					}
+					continue
+				}
+				if d.Ack != nil {
+					s.pending.Add(string(wrap.Content), d.Ack)
+				}
+				_, span := tracing.Start(tracing.Extract(ctx, wrap.Headers), tracing.SpanCollect, trace.WithAttributes(
+					attribute.String("alvarium.key", string(wrap.Content)),
+					attribute.String("alvarium.producer", wrap.Producer)))
+				s.traces.Collect(string(wrap.Content), span)
+				s.chKeys <- string(wrap.Content)
 			} else {
 				return
 			}
@@ -82,6 +126,27 @@ func (s *Subscriber) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) b
 	return true
 }
 
+// Connected reports the provider's connection state, for providers that track one
+func (s *Subscriber) Connected() bool {
+	if h, ok := s.instance.(interfaces.Health); ok {
+		return h.Connected()
+	}
+	return true
+}
+
+// openKey decodes a CalculateScore message. Keys are read from envelopes of the supported major versions, including
+// those published before the envelope was versioned, and must be sent as text.
+func openKey(payload []byte) (msg.SubscribeWrapper, error) {
+	wrap, err := msg.Open(payload, supportedVersions...)
+	if err != nil {
+		return wrap, err
+	}
+	if len(wrap.ContentType) > 0 && wrap.ContentType != msg.ContentTypeText {
+		return wrap, fmt.Errorf("unsupported content type %s", wrap.ContentType)
+	}
+	return wrap, nil
+}
+
 func logErrors(ch chan error, logger logInterface.Logger) {
 	for {
 		e, ok := <-ch
@@ -91,3 +156,14 @@ func logErrors(ch chan error, logger logInterface.Logger) {
 		logger.Error(e.Error())
 	}
 }
+
+// acknowledge completes the acknowledgements taken for a key, logging any that fail. An unacknowledged message is
+// redelivered by its provider, so a failure only costs a repeated score.
+func acknowledge(acks []func() error, logger logInterface.Logger) {
+	for _, ack := range acks {
+		err := ack()
+		if err != nil {
+			logger.Error(err.Error())
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package types
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// HashRing assigns keys to members using consistent hashing. Each member is placed on the ring several times so that
+// the key space is spread evenly, and only the keys owned by a departing member move when membership changes.
+type HashRing struct {
+	hashes   []uint32
+	members  []string
+	mutex    sync.RWMutex
+	owners   map[uint32]string
+	replicas int
+}
+
+func NewHashRing(replicas int) *HashRing {
+	if replicas < 1 {
+		replicas = 1
+	}
+	return &HashRing{
+		owners:   make(map[uint32]string),
+		replicas: replicas,
+	}
+}
+
+// Set replaces the ring's members. It returns true if the membership changed.
+func (hr *HashRing) Set(members []string) bool {
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	sort.Strings(sorted)
+
+	hr.mutex.Lock()
+	defer hr.mutex.Unlock()
+
+	if equal(sorted, hr.members) {
+		return false
+	}
+
+	hr.members = sorted
+	hr.hashes = nil
+	hr.owners = make(map[uint32]string)
+	for _, m := range sorted {
+		for i := 0; i < hr.replicas; i++ {
+			h := hash(m + "#" + strconv.Itoa(i))
+			hr.hashes = append(hr.hashes, h)
+			hr.owners[h] = m
+		}
+	}
+	sort.Slice(hr.hashes, func(i, j int) bool { return hr.hashes[i] < hr.hashes[j] })
+	return true
+}
+
+// Owner returns the member responsible for the given key, or an empty string if the ring has no members.
+func (hr *HashRing) Owner(key string) string {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+
+	if len(hr.hashes) == 0 {
+		return ""
+	}
+	h := hash(key)
+	idx := sort.Search(len(hr.hashes), func(i int) bool { return hr.hashes[i] >= h })
+	if idx == len(hr.hashes) {
+		idx = 0
+	}
+	return hr.owners[hr.hashes[idx]]
+}
+
+// Members returns the current members of the ring in sorted order.
+func (hr *HashRing) Members() []string {
+	hr.mutex.RLock()
+	defer hr.mutex.RUnlock()
+
+	members := make([]string, len(hr.members))
+	copy(members, hr.members)
+	return members
+}
+
+// hash only needs to spread similar strings evenly around the ring, md5 is used for its distribution and not for security.
+func hash(v string) uint32 {
+	h := md5.Sum([]byte(v))
+	return binary.BigEndian.Uint32(h[:4])
+}
+
+func equal(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
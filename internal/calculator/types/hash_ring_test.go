@@ -0,0 +1,67 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package types
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHashRingOwner(t *testing.T) {
+	hr := NewHashRing(64)
+	if hr.Owner("key") != "" {
+		t.Errorf("expected no owner for an empty ring")
+	}
+
+	hr.Set([]string{"a", "b", "c"})
+	counts := make(map[string]int)
+	owners := make(map[string]string)
+	for i := 0; i < 3000; i++ {
+		key := "key-" + strconv.Itoa(i)
+		owner := hr.Owner(key)
+		if owner != hr.Owner(key) {
+			t.Fatalf("owner of %s is not stable", key)
+		}
+		counts[owner]++
+		owners[key] = owner
+	}
+	for _, m := range []string{"a", "b", "c"} {
+		if counts[m] < 500 {
+			t.Errorf("member %s owns too few keys (%v)", m, counts[m])
+		}
+	}
+
+	// Removing a member should only move the keys it owned
+	if !hr.Set([]string{"c", "a"}) {
+		t.Fatalf("expected membership change to be reported")
+	}
+	for key, before := range owners {
+		after := hr.Owner(key)
+		if before != "b" && before != after {
+			t.Errorf("key %s moved from %s to %s", key, before, after)
+		}
+		if after == "b" {
+			t.Errorf("key %s still owned by departed member", key)
+		}
+	}
+}
+
+func TestHashRingSetUnchanged(t *testing.T) {
+	hr := NewHashRing(8)
+	hr.Set([]string{"a", "b"})
+	if hr.Set([]string{"b", "a"}) {
+		t.Errorf("expected reordered membership to be reported as unchanged")
+	}
+}
@@ -40,6 +40,14 @@ func (km *KeyMap) Add(key string) {
 	km.items[key] = time.Now()
 }
 
+// Len returns the number of keys in the map.
+func (km *KeyMap) Len() int {
+	km.mutex.Lock()
+	defer km.mutex.Unlock()
+
+	return len(km.items)
+}
+
 // Poll will return all of the keys that are ready for processing. This also removes the keys from the internal map.
 func (km *KeyMap) Poll(interval int64) []string {
 	km.mutex.Lock()
@@ -0,0 +1,67 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package types
+
+import (
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+)
+
+// KeyTraces follows the traces of the messages that requested a key. Each message's collect span lasts until the
+// Collector hands the key off, after which the spans' contexts wait for the key to be scored.
+type KeyTraces struct {
+	collecting map[string][]trace.Span
+	collected  map[string][]trace.SpanContext
+	mutex      sync.Mutex
+}
+
+func NewKeyTraces() *KeyTraces {
+	return &KeyTraces{
+		collecting: make(map[string][]trace.Span),
+		collected:  make(map[string][]trace.SpanContext),
+	}
+}
+
+// Collect records the collect span of a message requesting the key.
+func (kt *KeyTraces) Collect(key string, span trace.Span) {
+	kt.mutex.Lock()
+	defer kt.mutex.Unlock()
+
+	kt.collecting[key] = append(kt.collecting[key], span)
+}
+
+// Collected ends the key's collect spans once it has been handed off.
+func (kt *KeyTraces) Collected(key string) {
+	kt.mutex.Lock()
+	defer kt.mutex.Unlock()
+
+	for _, span := range kt.collecting[key] {
+		span.End()
+		if span.SpanContext().IsValid() {
+			kt.collected[key] = append(kt.collected[key], span.SpanContext())
+		}
+	}
+	delete(kt.collecting, key)
+}
+
+// Take removes and returns the contexts of the collect spans ended for the key so far.
+func (kt *KeyTraces) Take(key string) []trace.SpanContext {
+	kt.mutex.Lock()
+	defer kt.mutex.Unlock()
+
+	collected := kt.collected[key]
+	delete(kt.collected, key)
+	return collected
+}
@@ -0,0 +1,47 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package types
+
+import "sync"
+
+// PendingAcks holds the acknowledgements of the messages that requested a key until that key has been scored. Keys
+// from providers that don't acknowledge messages are never added.
+type PendingAcks struct {
+	items map[string][]func() error
+	mutex sync.Mutex
+}
+
+func NewPendingAcks() *PendingAcks {
+	return &PendingAcks{items: make(map[string][]func() error)}
+}
+
+// Add records an acknowledgement to be made once the key has been handled.
+func (p *PendingAcks) Add(key string, ack func() error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.items[key] = append(p.items[key], ack)
+}
+
+// Take removes and returns the acknowledgements recorded for the key so far. Messages for the key that arrive
+// afterwards wait for the next time it is handled.
+func (p *PendingAcks) Take(key string) []func() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	acks := p.items[key]
+	delete(p.items, key)
+	return acks
+}
@@ -0,0 +1,145 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type AlertRuleType string
+
+const (
+	RuleConfidence AlertRuleType = "confidence" // Fires when a score's confidence falls below Threshold
+	RuleAnnotation AlertRuleType = "annotation" // Fires when an annotation of the given Kind is not satisfied
+	RuleHost       AlertRuleType = "host"       // Fires when a host produces Count unsatisfied annotations within Window
+	// RuleOutbox is raised by the subscriber when its publish outbox overflows. It can't be configured as a rule.
+	RuleOutbox AlertRuleType = "outbox"
+)
+
+func (t AlertRuleType) Validate() bool {
+	if t == RuleConfidence || t == RuleAnnotation || t == RuleHost {
+		return true
+	}
+	return false
+}
+
+type AlertSinkType string
+
+const (
+	SinkLog     AlertSinkType = "log"
+	SinkMqtt    AlertSinkType = "mqtt"
+	SinkWebhook AlertSinkType = "webhook"
+)
+
+func (t AlertSinkType) Validate() bool {
+	if t == SinkLog || t == SinkMqtt || t == SinkWebhook {
+		return true
+	}
+	return false
+}
+
+// AlertingInfo defines the rules evaluated against each newly calculated score and the sinks that matching rules
+// will notify. Alerting is disabled when no rules are defined.
+type AlertingInfo struct {
+	Rules []AlertRule     `json:"rules,omitempty"`
+	Sinks []AlertSinkInfo `json:"sinks,omitempty"`
+}
+
+// AlertRule describes a single condition. Only the fields relevant to the rule's Type need to be populated.
+type AlertRule struct {
+	Name      string        `json:"name,omitempty"`      // Name uniquely identifies the rule and is included in the alert
+	Type      AlertRuleType `json:"type,omitempty"`      // Type indicates which condition the rule evaluates
+	Threshold float64       `json:"threshold,omitempty"` // Threshold is the confidence below which a "confidence" rule fires
+	Kind      string        `json:"kind,omitempty"`      // Kind is the annotation type observed by an "annotation" rule
+	Host      string        `json:"host,omitempty"`      // Host optionally restricts a "host" rule to a single host
+	Count     int           `json:"count,omitempty"`     // Count is the number of failures that trigger a "host" rule
+	Window    int64         `json:"window,omitempty"`    // Window is the period in milliseconds over which failures are counted
+	Cooldown  int64         `json:"cooldown,omitempty"`  // Cooldown in milliseconds during which a repeat alert for the same subject is suppressed
+	Sinks     []string      `json:"sinks,omitempty"`     // Sinks lists the names of the sinks to notify. Empty means all sinks.
+}
+
+func (r *AlertRule) UnmarshalJSON(data []byte) (err error) {
+	type Alias AlertRule
+	a := Alias{}
+	if err = json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	if !a.Type.Validate() {
+		return fmt.Errorf("invalid AlertRuleType value provided %s", a.Type)
+	}
+	if len(a.Name) == 0 {
+		return fmt.Errorf("alert rule of type %s requires a name", a.Type)
+	}
+	if a.Type == RuleHost && a.Count < 1 {
+		return fmt.Errorf("alert rule %s requires a count greater than zero", a.Name)
+	}
+	if a.Type == RuleAnnotation && len(a.Kind) == 0 {
+		return fmt.Errorf("alert rule %s requires an annotation kind", a.Name)
+	}
+	*r = AlertRule(a)
+	return nil
+}
+
+type AlertSinkInfo struct {
+	Name   string        `json:"name,omitempty"`
+	Type   AlertSinkType `json:"type,omitempty"`
+	Config interface{}   `json:"config,omitempty"`
+}
+
+// WebhookSinkConfig defines the endpoint to which alerts are POSTed as JSON
+type WebhookSinkConfig struct {
+	Url     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Timeout int64             `json:"timeout,omitempty"` // Timeout in milliseconds for each request
+}
+
+func (s *AlertSinkInfo) UnmarshalJSON(data []byte) (err error) {
+	type Alias struct {
+		Name string
+		Type AlertSinkType
+	}
+	a := Alias{}
+	if err = json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	if !a.Type.Validate() {
+		return fmt.Errorf("invalid AlertSinkType value provided %s", a.Type)
+	}
+	s.Name = a.Name
+	s.Type = a.Type
+
+	switch a.Type {
+	case SinkMqtt:
+		type mqttAlias struct {
+			Config MqttConfig `json:"config,omitempty"`
+		}
+		i := mqttAlias{}
+		if err = json.Unmarshal(data, &i); err != nil {
+			return err
+		}
+		s.Config = i.Config
+	case SinkWebhook:
+		type webhookAlias struct {
+			Config WebhookSinkConfig `json:"config,omitempty"`
+		}
+		i := webhookAlias{}
+		if err = json.Unmarshal(data, &i); err != nil {
+			return err
+		}
+		s.Config = i.Config
+	}
+	return nil
+}
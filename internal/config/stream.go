@@ -0,0 +1,239 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/contracts"
+)
+
+// StreamType extends the SDK's stream types with the platforms only these applications support.
+type StreamType string
+
+const (
+	FileStream   StreamType = "file"
+	HttpStream   StreamType = "http"
+	IotaStream   StreamType = StreamType(contracts.IotaStream)
+	KafkaStream  StreamType = "kafka"
+	MemoryStream StreamType = "memory"
+	MockStream   StreamType = StreamType(contracts.MockStream)
+	MqttStream   StreamType = StreamType(contracts.MqttStream)
+	NatsStream   StreamType = "nats"
+)
+
+func (t StreamType) Validate() bool {
+	if t == FileStream || t == HttpStream || t == KafkaStream || t == MemoryStream || t == NatsStream {
+		return true
+	}
+	return contracts.StreamType(t).Validate()
+}
+
+// StreamInfo describes a streaming platform endpoint. It reads the same JSON as the SDK's StreamInfo, whose config
+// types are reused, and adds the stream types the SDK doesn't know about.
+type StreamInfo struct {
+	Type   StreamType  `json:"type,omitempty"`
+	Config interface{} `json:"config,omitempty"`
+}
+
+func (s *StreamInfo) UnmarshalJSON(data []byte) (err error) {
+	type Alias struct {
+		Type StreamType `json:"type,omitempty"`
+	}
+	a := Alias{}
+	// Error with unmarshaling
+	if err = json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+
+	if !a.Type.Validate() {
+		return fmt.Errorf("invalid StreamType value provided %s", a.Type)
+	}
+
+	if a.Type == FileStream {
+		type fileAlias struct {
+			Type   StreamType       `json:"type,omitempty"`
+			Config FileStreamConfig `json:"config,omitempty"`
+		}
+		f := fileAlias{}
+		if err = json.Unmarshal(data, &f); err != nil {
+			return err
+		}
+		if len(f.Config.Path) == 0 {
+			return fmt.Errorf("stream type %s requires a path", f.Type)
+		}
+		s.Type = f.Type
+		s.Config = f.Config
+	} else if a.Type == HttpStream {
+		type httpAlias struct {
+			Type   StreamType       `json:"type,omitempty"`
+			Config HttpStreamConfig `json:"config,omitempty"`
+		}
+		h := httpAlias{}
+		if err = json.Unmarshal(data, &h); err != nil {
+			return err
+		}
+		if len(h.Config.Secret) == 0 {
+			return fmt.Errorf("stream type %s requires a secret", h.Type)
+		}
+		s.Type = h.Type
+		s.Config = h.Config
+	} else if a.Type == KafkaStream {
+		type kafkaAlias struct {
+			Type   StreamType  `json:"type,omitempty"`
+			Config KafkaConfig `json:"config,omitempty"`
+		}
+		k := kafkaAlias{}
+		if err = json.Unmarshal(data, &k); err != nil {
+			return err
+		}
+		s.Type = k.Type
+		s.Config = k.Config
+	} else if a.Type == MemoryStream {
+		type memoryAlias struct {
+			Type   StreamType   `json:"type,omitempty"`
+			Config MemoryConfig `json:"config,omitempty"`
+		}
+		m := memoryAlias{}
+		if err = json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		s.Type = m.Type
+		s.Config = m.Config
+	} else if a.Type == NatsStream {
+		type natsAlias struct {
+			Type   StreamType `json:"type,omitempty"`
+			Config NatsConfig `json:"config,omitempty"`
+		}
+		n := natsAlias{}
+		if err = json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		s.Type = n.Type
+		s.Config = n.Config
+	} else if a.Type == IotaStream {
+		type iotaAlias struct {
+			Type   StreamType `json:"type,omitempty"`
+			Config IotaConfig `json:"config,omitempty"`
+		}
+		i := iotaAlias{}
+		if err = json.Unmarshal(data, &i); err != nil {
+			return err
+		}
+		s.Type = i.Type
+		s.Config = i.Config
+	} else if a.Type == MqttStream {
+		type mqttAlias struct {
+			Type   StreamType `json:"type,omitempty"`
+			Config MqttConfig `json:"config,omitempty"`
+		}
+		m := mqttAlias{}
+		if err = json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		s.Type = m.Type
+		s.Config = m.Config
+	} else {
+		sdk := config.StreamInfo{}
+		if err = json.Unmarshal(data, &sdk); err != nil {
+			return err
+		}
+		s.Type = StreamType(sdk.Type)
+		s.Config = sdk.Config
+	}
+	return nil
+}
+
+// SdkInfo mirrors the parts of the SDK configuration read by these applications so that the annotation stream can
+// use any StreamType.
+type SdkInfo struct {
+	Stream StreamInfo `json:"stream,omitempty"`
+}
+
+// SourceInfo names one of several annotation streams consumed by the subscriber. The name is recorded on every
+// annotation received from the stream.
+type SourceInfo struct {
+	Name       string         `json:"name,omitempty"`
+	Stream     StreamInfo     `json:"stream,omitempty"`
+	Validation ValidationInfo `json:"validation,omitempty"` // Validation overrides the subscriber's validation for this stream
+}
+
+// FileStreamConfig replays annotation messages previously recorded to a JSON lines file
+type FileStreamConfig struct {
+	Path  string  `json:"path,omitempty"`  // Path of the recording
+	Speed float64 `json:"speed,omitempty"` // Speed multiplies the pace at which messages were recorded, 1 if unset
+}
+
+// HttpStreamConfig exposes properties relevant to receiving annotations posted to a webhook
+type HttpStreamConfig struct {
+	Endpoint        config.ServiceInfo `json:"endpoint,omitempty"`        // Endpoint is the address the webhook listens on
+	Path            string             `json:"path,omitempty"`            // Path annotations are posted to, defaults to /annotations
+	Header          string             `json:"header,omitempty"`          // Header carrying the hex HMAC-SHA256 of the timestamp and body, defaults to X-Alvarium-Signature
+	Secret          string             `json:"secret,omitempty"`          // Secret is the key shared with the annotators to sign their requests
+	TimestampHeader string             `json:"timestampHeader,omitempty"` // TimestampHeader carries the signing time in Unix seconds, defaults to X-Alvarium-Timestamp
+	Tolerance       int64              `json:"tolerance,omitempty"`       // Tolerance is how many milliseconds a timestamp may differ from the subscriber's clock, defaults to 300000
+}
+
+// KafkaConfig exposes properties relevant to connecting to a Kafka cluster
+type KafkaConfig struct {
+	Brokers  []string `json:"brokers,omitempty"`  // Brokers lists the host:port bootstrap addresses
+	ClientId string   `json:"clientId,omitempty"` // ClientId identifies this client to the brokers
+	GroupId  string   `json:"groupId,omitempty"`  // GroupId is the consumer group. Replicas sharing it split the partitions between them.
+	Topics   []string `json:"topics,omitempty"`   // Topics are consumed from, or published to, in full
+}
+
+// IotaConfig extends the SDK's IOTA Streams config with the file the subscriber keeps its identity and sync position
+// in. The SDK properties are read from the same level of the JSON.
+type IotaConfig struct {
+	config.IotaStreamConfig
+	StatePath    string `json:"statePath,omitempty"`    // StatePath is where the subscriber state is kept. A new identity is created on every start if empty.
+	PreSharedKey string `json:"preSharedKey,omitempty"` // PreSharedKey for this channel, overriding the application's preSharedKey
+}
+
+// MqttConfig extends the SDK's MQTT config with transport security. The SDK properties are read from the same level
+// of the JSON, so existing configuration is unchanged.
+type MqttConfig struct {
+	config.MqttConfig
+	Tls                  TlsInfo `json:"tls,omitempty"`                  // Tls is used when the provider protocol is ssl, tls or mqtts
+	MaxReconnectInterval int64   `json:"maxReconnectInterval,omitempty"` // MaxReconnectInterval caps the reconnect backoff in milliseconds, defaults to 30000
+}
+
+// TlsInfo configures TLS for a client connection. Client certificates are only presented when both files are set.
+type TlsInfo struct {
+	CaFile             string `json:"caFile,omitempty"`             // CaFile is a PEM bundle trusted in addition to the system roots
+	CertFile           string `json:"certFile,omitempty"`           // CertFile is the PEM client certificate for mutual TLS
+	KeyFile            string `json:"keyFile,omitempty"`            // KeyFile is the PEM private key of CertFile
+	ServerName         string `json:"serverName,omitempty"`         // ServerName overrides the host name verified against the broker's certificate
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // InsecureSkipVerify disables certificate verification, for development only
+}
+
+// MemoryConfig connects to a broker held in memory by the process, for running services together in one binary or
+// one test. Publishers and subscribers naming the same broker and topics exchange messages.
+type MemoryConfig struct {
+	Broker string   `json:"broker,omitempty"` // Broker names the in-process broker, defaults to "default"
+	Topics []string `json:"topics,omitempty"` // Topics are published to, and subscribed to, in full
+	Buffer int      `json:"buffer,omitempty"` // Buffer is how many messages a subscriber may fall behind before publishers wait for it
+}
+
+// NatsConfig exposes properties relevant to publishing to and consuming from a NATS JetStream stream
+type NatsConfig struct {
+	Provider   config.ServiceInfo `json:"provider,omitempty"`   // Provider is the NATS server, e.g. protocol "nats" and port 4222
+	Stream     string             `json:"stream,omitempty"`     // Stream is the JetStream stream, created with Subjects if it doesn't exist
+	Subjects   []string           `json:"subjects,omitempty"`   // Subjects are published to, and consumed from, in full
+	Durable    string             `json:"durable,omitempty"`    // Durable names the consumer so that unacknowledged messages survive restarts
+	AckWait    int64              `json:"ackWait,omitempty"`    // AckWait in milliseconds before an unacknowledged message is redelivered
+	MaxDeliver int                `json:"maxDeliver,omitempty"` // MaxDeliver limits redeliveries of a message, unlimited if not set
+}
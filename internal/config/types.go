@@ -200,8 +200,167 @@ func (p *LocalPolicyConfig) UnmarshalJSON(data []byte) (err error) {
 	return nil
 }
 
+type DeadLetterType string
+
+const (
+	DeadLetterArango DeadLetterType = "arango"
+	DeadLetterFile   DeadLetterType = "file"
+)
+
+func (t DeadLetterType) Validate() bool {
+	if t == DeadLetterArango || t == DeadLetterFile {
+		return true
+	}
+	return false
+}
+
+// DeadLetterInfo configures where messages that fail ingestion are recorded. Dead-lettering is disabled if no type is
+// provided.
+type DeadLetterInfo struct {
+	Type       DeadLetterType `json:"type,omitempty"`
+	Collection string         `json:"collection,omitempty"` // Collection is the Arango collection used by the "arango" type
+	Path       string         `json:"path,omitempty"`       // Path is the JSON lines file used by the "file" type
+}
+
+func (d *DeadLetterInfo) UnmarshalJSON(data []byte) (err error) {
+	type Alias DeadLetterInfo
+	a := Alias{}
+	if err = json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	if len(a.Type) > 0 && !a.Type.Validate() {
+		return fmt.Errorf("invalid DeadLetterType value provided %s", a.Type)
+	}
+	if a.Type == DeadLetterFile && len(a.Path) == 0 {
+		return fmt.Errorf("dead letter type %s requires a path", a.Type)
+	}
+	*d = DeadLetterInfo(a)
+	return nil
+}
+
+type ValidationMode string
+
+const (
+	ValidationLenient ValidationMode = "lenient"
+	ValidationStrict  ValidationMode = "strict"
+)
+
+func (m ValidationMode) Validate() bool {
+	if m == ValidationLenient || m == ValidationStrict {
+		return true
+	}
+	return false
+}
+
+// ValidationInfo controls how incoming AnnotationLists are checked before ingestion. In strict mode any invalid
+// annotation rejects the whole list, while lenient mode drops the invalid annotations and ingests the rest. Lists that
+// are structurally unusable, such as a mutate without a source annotation, are rejected in both modes.
+type ValidationInfo struct {
+	Mode ValidationMode `json:"mode,omitempty"` // Mode defaults to lenient
+}
+
+func (v *ValidationInfo) UnmarshalJSON(data []byte) (err error) {
+	type Alias ValidationInfo
+	a := Alias{}
+	if err = json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	if len(a.Mode) > 0 && !a.Mode.Validate() {
+		return fmt.Errorf("invalid ValidationMode value provided %s", a.Mode)
+	}
+	*v = ValidationInfo(a)
+	return nil
+}
+
+// MetricsInfo exposes the service's Prometheus metrics over HTTP. Metrics are not served unless a port is provided.
+type MetricsInfo struct {
+	Port int    `json:"port,omitempty"`
+	Path string `json:"path,omitempty"` // Path defaults to /metrics
+}
+
+// HealthInfo exposes the service's liveness and readiness over HTTP. Without a port they are served alongside the
+// metrics, and not at all if neither port is provided.
+type HealthInfo struct {
+	Port int `json:"port,omitempty"`
+}
+
+type TraceExporterType string
+
+const (
+	TraceExporterOtlp   TraceExporterType = "otlp"
+	TraceExporterStdout TraceExporterType = "stdout"
+)
+
+func (t TraceExporterType) Validate() bool {
+	if t == TraceExporterOtlp || t == TraceExporterStdout {
+		return true
+	}
+	return false
+}
+
+// TracingInfo exports an OpenTelemetry span for each stage a piece of data passes through. Tracing is disabled if no
+// exporter is provided, though trace context received in messages is still passed on.
+type TracingInfo struct {
+	Exporter    TraceExporterType `json:"exporter,omitempty"`
+	Endpoint    string            `json:"endpoint,omitempty"`    // Endpoint is the host:port of the OTLP/HTTP collector, localhost:4318 by default
+	Insecure    bool              `json:"insecure,omitempty"`    // Insecure sends spans to the collector over plain HTTP
+	SampleRatio float64           `json:"sampleRatio,omitempty"` // SampleRatio is the fraction of new traces recorded, all of them if not set
+}
+
+func (t *TracingInfo) UnmarshalJSON(data []byte) (err error) {
+	type Alias TracingInfo
+	a := Alias{}
+	if err = json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	if len(a.Exporter) > 0 && !a.Exporter.Validate() {
+		return fmt.Errorf("invalid TraceExporterType value provided %s", a.Exporter)
+	}
+	if a.SampleRatio < 0 || a.SampleRatio > 1 {
+		return fmt.Errorf("tracing sampleRatio must be between 0 and 1")
+	}
+	*t = TracingInfo(a)
+	return nil
+}
+
+// RecordingInfo taps the annotation stream, appending every message received to a JSON lines file that can later be
+// replayed through a stream of type "file". Recording is disabled if no path is provided.
+type RecordingInfo struct {
+	Path string `json:"path,omitempty"`
+}
+
+// RetryInfo controls how often a failed operation is attempted before it is given up on
+type RetryInfo struct {
+	MaxAttempts int   `json:"maxAttempts,omitempty"` // MaxAttempts is the total number of attempts, including the first
+	Interval    int64 `json:"interval,omitempty"`    // Interval in milliseconds, multiplied by the attempt number between attempts
+}
+
+// OutboxInfo bounds how many keys the subscriber holds in memory while they can't be published. Further keys are
+// spilled to the file at Path and the sinks under Alerts are notified that spilling has begun. Without a Path, ingestion
+// waits for the outbox to drain instead. Publishing a key is retried, waiting Retry.Interval multiplied by the attempt
+// number. After Retry.MaxAttempts failed attempts the key is moved behind the other keys waiting and the sinks are
+// notified, so a key that can't be published doesn't hold back the rest.
+type OutboxInfo struct {
+	Capacity int             `json:"capacity,omitempty"` // Capacity is the number of keys held in memory
+	Path     string          `json:"path,omitempty"`     // Path to the spill file, which also keeps unpublished keys across restarts
+	Retry    RetryInfo       `json:"retry,omitempty"`
+	Alerts   []AlertSinkInfo `json:"alerts,omitempty"`
+}
+
+// ShardingInfo allows several calculator replicas to partition the key space between them. Each replica records a
+// heartbeat in an Arango document collection and only scores the keys it owns on a consistent hash ring built from
+// the replicas whose heartbeats have not expired.
+type ShardingInfo struct {
+	Enabled    bool   `json:"enabled,omitempty"`
+	InstanceId string `json:"instanceId,omitempty"` // InstanceId uniquely identifies the replica. Defaults to the hostname.
+	Collection string `json:"collection,omitempty"` // Collection is the name of the membership collection.
+	Heartbeat  int64  `json:"heartbeat,omitempty"`  // Heartbeat is the interval in milliseconds between heartbeats.
+	Expiry     int64  `json:"expiry,omitempty"`     // Expiry in milliseconds after which a silent replica is considered gone.
+	Replicas   int    `json:"replicas,omitempty"`   // Replicas is the number of points each replica occupies on the hash ring.
+}
+
 // PubSubInfo encapsulates endpoint definitions for publishing and subscribing to the relevant platform providers.
 type PubSubInfo struct {
-	Publish   config.StreamInfo `json:"publisher,omitempty"`  //Defines the publisher endpoint
-	Subscribe config.StreamInfo `json:"subscriber,omitempty"` //Defines the subscriber endpoint
+	Publish   StreamInfo `json:"publisher,omitempty"`  //Defines the publisher endpoint
+	Subscribe StreamInfo `json:"subscriber,omitempty"` //Defines the subscriber endpoint
 }
@@ -72,15 +72,27 @@ func NewArangoClient(configs []config.DatabaseInfo, logger logInterface.Logger)
 	return &client, nil
 }
 
-func (c *ArangoClient) QueryScore(ctx context.Context, key string) (documents.Score, error) {
+// Check reports whether the Arango server can be reached
+func (c *ArangoClient) Check(ctx context.Context) error {
+	_, err := c.instance.Version(ctx)
+	return err
+}
+
+// QueryScore returns the most recent score for the given key. When classifier is provided, only scores calculated
+// with that policy are considered.
+func (c *ArangoClient) QueryScore(ctx context.Context, key string, classifier string) (documents.Score, error) {
 	db, err := c.instance.Database(ctx, c.cfg.DatabaseName)
 	if err != nil {
 		return documents.Score{}, err
 	}
-	query := "FOR s in scores FILTER s.dataRef == @key RETURN s"
+	query := "FOR s in scores FILTER s.dataRef == @key SORT s.timestamp DESC LIMIT 1 RETURN s"
 	bindVars := map[string]interface{}{
 		"key": key,
 	}
+	if len(classifier) > 0 {
+		query = "FOR s in scores FILTER s.dataRef == @key AND s.policy == @policy SORT s.timestamp DESC LIMIT 1 RETURN s"
+		bindVars["policy"] = classifier
+	}
 	cursor, err := db.Query(ctx, query, bindVars)
 	if err != nil {
 		return documents.Score{}, err
@@ -100,6 +112,38 @@ func (c *ArangoClient) QueryScore(ctx context.Context, key string) (documents.Sc
 	return score, nil
 }
 
+// QueryScores returns the most recent score for the given key per classifier
+func (c *ArangoClient) QueryScores(ctx context.Context, key string) ([]documents.Score, error) {
+	db, err := c.instance.Database(ctx, c.cfg.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	query := `FOR s in scores FILTER s.dataRef == @key
+		COLLECT policy = s.policy INTO scored
+		RETURN FIRST(FOR x IN scored[*].s SORT x.timestamp DESC RETURN x)`
+	bindVars := map[string]interface{}{
+		"key": key,
+	}
+	cursor, err := db.Query(ctx, query, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	var scores []documents.Score
+	for {
+		var doc documents.Score
+		_, err := cursor.ReadDocument(ctx, &doc)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		scores = append(scores, doc)
+	}
+	return scores, nil
+}
+
 func (c *ArangoClient) QueryAnnotations(ctx context.Context, key string) ([]documents.Annotation, error) {
 	db, err := c.instance.Database(ctx, c.cfg.DatabaseName)
 	if err != nil {
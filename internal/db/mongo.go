@@ -134,6 +134,11 @@ func (mp *MongoProvider) Close(ctx context.Context) error {
 	return mp.instance.Disconnect(ctx)
 }
 
+// Check reports whether the Mongo server can be reached
+func (mp *MongoProvider) Check(ctx context.Context) error {
+	return mp.instance.Ping(ctx, nil)
+}
+
 func (mp *MongoProvider) buildConnectionString() string {
 	return fmt.Sprintf("mongodb://%s:%s@%s:%v", mp.cfg.Username, mp.cfg.Password, mp.cfg.Host, mp.cfg.Port)
 }
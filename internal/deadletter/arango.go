@@ -0,0 +1,132 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package deadletter
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+	"github.com/arangodb/go-driver/http"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+)
+
+const defaultCollection string = "deadletters"
+
+type arangoStore struct {
+	cfg        config.ArangoConfig
+	client     driver.Client
+	collection string
+	logger     logInterface.Logger
+}
+
+func newArangoStore(collection string, cfg config.ArangoConfig, logger logInterface.Logger) (*arangoStore, error) {
+	if len(collection) == 0 {
+		collection = defaultCollection
+	}
+	conn, err := http.NewConnection(
+		http.ConnectionConfig{
+			Endpoints: []string{cfg.Provider.Uri()},
+		})
+	if err != nil {
+		return nil, err
+	}
+	client, err := driver.NewClient(
+		driver.ClientConfig{
+			Connection: conn,
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &arangoStore{
+		cfg:        cfg,
+		client:     client,
+		collection: collection,
+		logger:     logger,
+	}, nil
+}
+
+func (s *arangoStore) Write(ctx context.Context, letter documents.DeadLetter) error {
+	coll, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = coll.CreateDocument(driver.WithOverwriteMode(ctx, driver.OverwriteModeReplace), letter)
+	return err
+}
+
+func (s *arangoStore) List(ctx context.Context) ([]documents.DeadLetter, error) {
+	db, err := s.client.Database(ctx, s.cfg.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := db.CollectionExists(ctx, s.collection)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	query := "FOR d IN @@collection SORT d.timestamp ASC RETURN d"
+	bindVars := map[string]interface{}{
+		"@collection": s.collection,
+	}
+	cursor, err := db.Query(ctx, query, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	var letters []documents.DeadLetter
+	for {
+		var doc documents.DeadLetter
+		_, err := cursor.ReadDocument(ctx, &doc)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		letters = append(letters, doc)
+	}
+	return letters, nil
+}
+
+func (s *arangoStore) Remove(ctx context.Context, key string) error {
+	coll, err := s.open(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = coll.RemoveDocument(ctx, key)
+	return err
+}
+
+// open returns the dead letter collection, creating it the first time it is needed.
+func (s *arangoStore) open(ctx context.Context) (driver.Collection, error) {
+	db, err := s.client.Database(ctx, s.cfg.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := db.CollectionExists(ctx, s.collection)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		s.logger.Write(logging.DebugLevel, "creating collection "+s.collection)
+		_, err = db.CreateCollection(ctx, s.collection, nil)
+		if err != nil && !driver.IsConflict(err) {
+			return nil, err
+		}
+	}
+	return db.Collection(ctx, s.collection)
+}
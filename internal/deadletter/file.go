@@ -0,0 +1,144 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package deadletter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"os"
+	"sync"
+)
+
+// fileStore keeps dead letters in a local JSON lines file, one letter per line. It suits deployments where the database
+// itself may be the reason ingestion is failing.
+type fileStore struct {
+	mutex sync.Mutex
+	path  string
+}
+
+func newFileStore(path string) *fileStore {
+	return &fileStore{path: path}
+}
+
+func (s *fileStore) Write(ctx context.Context, letter documents.DeadLetter) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	letters, err := s.read()
+	if err != nil {
+		return err
+	}
+	for _, l := range letters {
+		if l.Key == letter.Key {
+			return s.rewrite(append(without(letters, letter.Key), letter))
+		}
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+func (s *fileStore) List(ctx context.Context) ([]documents.DeadLetter, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.read()
+}
+
+func (s *fileStore) Remove(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	letters, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.rewrite(without(letters, key))
+}
+
+func (s *fileStore) read() ([]documents.DeadLetter, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var letters []documents.DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var letter documents.DeadLetter
+		err = json.Unmarshal(scanner.Bytes(), &letter)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, scanner.Err()
+}
+
+// rewrite replaces the file's contents through a temporary file so a crash can't leave it half written.
+func (s *fileStore) rewrite(letters []documents.DeadLetter) error {
+	tmp := s.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, l := range letters {
+		b, err := json.Marshal(l)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(append(b, '\n'))
+	}
+	err = w.Flush()
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func without(letters []documents.DeadLetter, key string) []documents.DeadLetter {
+	var result []documents.DeadLetter
+	for _, l := range letters {
+		if l.Key != key {
+			result = append(result, l)
+		}
+	}
+	return result
+}
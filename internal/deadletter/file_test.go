@@ -0,0 +1,70 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	s := newFileStore(filepath.Join(t.TempDir(), "deadletters.jsonl"))
+
+	letters, err := s.List(ctx)
+	if err != nil || len(letters) != 0 {
+		t.Fatalf("expected empty store, got %v %v", letters, err)
+	}
+
+	first := documents.NewDeadLetter(message.SubscribeWrapper{Action: message.ActionCreate, Content: []byte("{}")}, errors.New("boom"), 3)
+	second := documents.NewRawDeadLetter([]byte("not json"), errors.New("bad payload"))
+	for _, l := range []documents.DeadLetter{first, second} {
+		if err := s.Write(ctx, l); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	first.Attempts = 4
+	if err := s.Write(ctx, first); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	letters, err = s.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(letters) != 2 {
+		t.Fatalf("expected 2 letters, got %v", len(letters))
+	}
+	for _, l := range letters {
+		if l.Key == first.Key && (l.Attempts != 4 || string(l.Message.Content) != "{}") {
+			t.Errorf("letter not replaced %v", l)
+		}
+		if l.Key == second.Key && string(l.Raw) != "not json" {
+			t.Errorf("raw payload not preserved %v", l)
+		}
+	}
+
+	if err := s.Remove(ctx, first.Key); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	letters, _ = s.List(ctx)
+	if len(letters) != 1 || letters[0].Key != second.Key {
+		t.Errorf("expected only %s to remain, got %v", second.Key, letters)
+	}
+}
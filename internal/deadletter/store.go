@@ -0,0 +1,53 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+)
+
+// Store records messages that could not be ingested so they can be inspected and re-driven instead of being lost.
+type Store interface {
+	// Write records the dead letter, replacing any existing letter with the same key.
+	Write(ctx context.Context, letter documents.DeadLetter) error
+	// List returns every recorded dead letter, oldest first.
+	List(ctx context.Context) ([]documents.DeadLetter, error)
+	// Remove deletes the dead letter with the given key.
+	Remove(ctx context.Context, key string) error
+}
+
+// NewStore returns the Store described by cfg. Dead-lettering is optional, so a nil Store is returned without error
+// when no type is configured. The database config is only used by the "arango" type.
+func NewStore(cfg config.DeadLetterInfo, dbConfig config.DatabaseInfo, logger logInterface.Logger) (Store, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case config.DeadLetterArango:
+		arangoCfg, ok := dbConfig.Config.(config.ArangoConfig)
+		if !ok {
+			return nil, errors.New("unknown type cast to ArangoConfig failed")
+		}
+		return newArangoStore(cfg.Collection, arangoCfg, logger)
+	case config.DeadLetterFile:
+		return newFileStore(cfg.Path), nil
+	default:
+		return nil, fmt.Errorf("unrecognized dead letter type %s", cfg.Type)
+	}
+}
@@ -0,0 +1,111 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+/*
+Package metrics serves the Prometheus metrics each application registers for its own stages of the pipeline, along with
+the health and readiness of the application.
+*/
+package metrics
+
+import (
+	"encoding/json"
+	"github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Namespace prefixes the name of every metric
+const Namespace = "alvarium"
+
+const (
+	defaultPath = "/metrics"
+	livePath    = "/health/live"
+	readyPath   = "/health/ready"
+)
+
+// HttpServer exposes the metrics registered with the default Prometheus registry, and reports status on the health
+// endpoints. The health endpoints share the metrics port unless a port of their own is configured.
+type HttpServer struct {
+	config config.MetricsInfo
+	health config.HealthInfo
+	logger interfaces.Logger
+	status *bootstrap.Status
+}
+
+func NewHttpServer(config config.MetricsInfo, health config.HealthInfo, status *bootstrap.Status,
+	logger interfaces.Logger) *HttpServer {
+	if health.Port == 0 {
+		health.Port = config.Port
+	}
+	return &HttpServer{
+		config: config,
+		health: health,
+		logger: logger,
+		status: status,
+	}
+}
+
+// Handlers returns the bootstrap handlers of the servers needed for the configured ports, tracked by the status like
+// any other component. It is empty if neither metrics nor health has a port.
+func (b *HttpServer) Handlers() []bootstrap.BootstrapHandler {
+	var handlers []bootstrap.BootstrapHandler
+	if b.config.Port > 0 {
+		path := b.config.Path
+		if len(path) == 0 {
+			path = defaultPath
+		}
+		mux := http.NewServeMux()
+		mux.Handle(path, promhttp.Handler())
+		if b.health.Port == b.config.Port {
+			b.handleHealth(mux)
+		}
+		server := bootstrap.NewHttpServer("Metrics", b.config.Port, mux, b.logger)
+		handlers = append(handlers, b.status.Handler("metrics", server.BootstrapHandler))
+	}
+	if b.health.Port > 0 && b.health.Port != b.config.Port {
+		mux := http.NewServeMux()
+		b.handleHealth(mux)
+		server := bootstrap.NewHttpServer("Health", b.health.Port, mux, b.logger)
+		handlers = append(handlers, b.status.Handler("health", server.BootstrapHandler))
+	}
+	return handlers
+}
+
+func (b *HttpServer) handleHealth(mux *http.ServeMux) {
+	mux.HandleFunc(livePath, func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, b.status.Live(), b.logger)
+	})
+	mux.HandleFunc(readyPath, func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, b.status.Ready(r.Context()), b.logger)
+	})
+}
+
+// writeReport responds with the report, using the status code that probes expect of an unhealthy application
+func writeReport(w http.ResponseWriter, report bootstrap.Report, logger interfaces.Logger) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		logger.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	if report.Healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(b)
+}
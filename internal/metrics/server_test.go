@@ -0,0 +1,96 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package metrics
+
+import (
+	"context"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestHandlers(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+
+	tests := []struct {
+		name     string
+		metrics  config.MetricsInfo
+		health   config.HealthInfo
+		expected int
+	}{
+		{"nothing", config.MetricsInfo{}, config.HealthInfo{}, 0},
+		{"metrics only", config.MetricsInfo{Port: 9101}, config.HealthInfo{}, 1},
+		{"health only", config.MetricsInfo{}, config.HealthInfo{Port: 9101}, 1},
+		{"shared port", config.MetricsInfo{Port: 9101}, config.HealthInfo{Port: 9101}, 1},
+		{"separate ports", config.MetricsInfo{Port: 9101}, config.HealthInfo{Port: 9201}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := NewHttpServer(tt.metrics, tt.health, bootstrap.NewStatus(), logger).Handlers()
+			if len(handlers) != tt.expected {
+				t.Errorf("expected %v servers, got %v", tt.expected, len(handlers))
+			}
+		})
+	}
+}
+
+func TestHealthWithoutMetrics(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	for _, h := range NewHttpServer(config.MetricsInfo{}, config.HealthInfo{Port: port}, bootstrap.NewStatus(), logger).Handlers() {
+		if !h(ctx, &wg) {
+			t.Fatalf("failed to start the health server")
+		}
+	}
+
+	base := "http://localhost:" + strconv.Itoa(port)
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{livePath, http.StatusOK},
+		{readyPath, http.StatusOK},
+		{defaultPath, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(base + tt.path)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %v, got %v", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -28,6 +28,9 @@ type ApplicationConfig struct {
 	Endpoint  SdkConfig.ServiceInfo     `json:"endpoint,omitempty"`
 	Hash      SdkConfig.HashInfo        `json:"hash,omitempty"`
 	Logging   LoggingConfig.LoggingInfo `json:"logging,omitempty"`
+	Metrics   config.MetricsInfo        `json:"metrics,omitempty"`
+	Health    config.HealthInfo         `json:"health,omitempty"`
+	Tracing   config.TracingInfo        `json:"tracing,omitempty"`
 }
 
 func (a ApplicationConfig) AsString() string {
@@ -0,0 +1,59 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package populator_api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestObserveRequests(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(observeRequests)
+	r.HandleFunc("/data/{id}/scores", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	for _, id := range []string{"a", "b"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/data/"+id+"/scores", nil))
+	}
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() != "alvarium_populator_api_request_duration_seconds" {
+			continue
+		}
+		if len(f.Metric) != 1 {
+			t.Fatalf("expected requests for different ids to share a series, got %v", len(f.Metric))
+		}
+		labels := make(map[string]string)
+		for _, l := range f.Metric[0].Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["route"] != "/data/{id}/scores" || labels["status"] != "404" || labels["method"] != http.MethodGet {
+			t.Errorf("unexpected labels %v", labels)
+		}
+		if f.Metric[0].Histogram.GetSampleCount() < 2 {
+			t.Errorf("expected both requests observed, got %v", f.Metric[0].Histogram.GetSampleCount())
+		}
+		return
+	}
+	t.Errorf("request duration not gathered")
+}
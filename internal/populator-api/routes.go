@@ -23,7 +23,10 @@ import (
 	"github.com/project-alvarium/scoring-apps-go/internal/db"
 	"github.com/project-alvarium/scoring-apps-go/internal/hashprovider"
 	"github.com/project-alvarium/scoring-apps-go/internal/models"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"github.com/project-alvarium/scoring-apps-go/pkg/responses"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
 	"strconv"
 	"time"
@@ -37,6 +40,8 @@ const (
 )
 
 func LoadRestRoutes(r *mux.Router, dbArango *db.ArangoClient, dbMongo *db.MongoProvider, logger interfaces.Logger) {
+	r.Use(observeRequests)
+
 	r.HandleFunc("/",
 		func(w http.ResponseWriter, r *http.Request) {
 			getIndexHandler(w, r, logger)
@@ -56,6 +61,11 @@ func LoadRestRoutes(r *mux.Router, dbArango *db.ArangoClient, dbMongo *db.MongoP
 		func(w http.ResponseWriter, r *http.Request) {
 			getAnnotationsHandler(w, r, dbMongo, dbArango, logger)
 		}).Methods(http.MethodGet)
+
+	r.HandleFunc("/data/{id}/scores",
+		func(w http.ResponseWriter, r *http.Request) {
+			getScoresHandler(w, r, dbMongo, dbArango, logger)
+		}).Methods(http.MethodGet)
 }
 
 func getIndexHandler(w http.ResponseWriter, r *http.Request, logger interfaces.Logger) {
@@ -166,3 +176,60 @@ func getAnnotationsHandler(w http.ResponseWriter, r *http.Request, dbMongo *db.M
 	w.WriteHeader(http.StatusOK)
 	w.Write(b)
 }
+
+func getScoresHandler(w http.ResponseWriter, r *http.Request, dbMongo *db.MongoProvider, dbArango *db.ArangoClient, logger interfaces.Logger) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	if len(id) == 0 {
+		errMsg := "Bad request: no id provided"
+		logger.Write(logging.DebugLevel, errMsg)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(errMsg))
+		return
+	}
+
+	record, err := dbMongo.FetchById(r.Context(), id)
+	if err != nil {
+		logger.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	sampleData := models.SampleFromMongoRecord(record)
+	b, _ := json.Marshal(sampleData)
+	key := hashprovider.DeriveHash(b)
+
+	start := time.Now()
+	scores, err := dbArango.QueryScores(r.Context(), key)
+	if err != nil {
+		logger.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	// Serving the scores is linked to the traces that calculated them
+	var links []trace.Link
+	for _, s := range scores {
+		sc := trace.SpanContextFromContext(tracing.Extract(r.Context(), s.Trace))
+		if sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	_, span := tracing.Start(r.Context(), tracing.SpanPopulate, trace.WithTimestamp(start), trace.WithLinks(links...),
+		trace.WithAttributes(attribute.String("alvarium.key", key)))
+	defer span.End()
+
+	response := responses.ScoreListResponse{
+		Count:  len(scores),
+		Scores: scores,
+	}
+	b, _ = json.Marshal(response)
+	w.Header().Add(headerKeyContentType, headerValueJson)
+	w.Header().Add(headerCORS, headerCORSValue)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -24,9 +24,13 @@ import (
 // ApplicationConfig serves as the root node for configuration and contains targeted child types with specialized
 // concerns.
 type ApplicationConfig struct {
-	Databases []config.DatabaseInfo     `json:"databases,omitempty"`
-	Hash      SdkConfig.HashInfo        `json:"hash,omitempty"`
-	Logging   LoggingConfig.LoggingInfo `json:"logging,omitempty"`
+	Classifier string                    `json:"classifier,omitempty"` // Classifier selects which policy's score is populated. Empty uses the most recent score.
+	Databases  []config.DatabaseInfo     `json:"databases,omitempty"`
+	Hash       SdkConfig.HashInfo        `json:"hash,omitempty"`
+	Logging    LoggingConfig.LoggingInfo `json:"logging,omitempty"`
+	Metrics    config.MetricsInfo        `json:"metrics,omitempty"`
+	Health     config.HealthInfo         `json:"health,omitempty"`
+	Tracing    config.TracingInfo        `json:"tracing,omitempty"`
 }
 
 func (a ApplicationConfig) AsString() string {
@@ -23,21 +23,26 @@ import (
 	"github.com/project-alvarium/scoring-apps-go/internal/db"
 	"github.com/project-alvarium/scoring-apps-go/internal/hashprovider"
 	"github.com/project-alvarium/scoring-apps-go/internal/models"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"sync"
 	"time"
 )
 
 type Worker struct {
-	dbArango *db.ArangoClient
-	dbMongo  *db.MongoProvider
-	logger   interfaces.Logger
+	classifier string
+	dbArango   *db.ArangoClient
+	dbMongo    *db.MongoProvider
+	logger     interfaces.Logger
 }
 
-func NewWorker(dbArango *db.ArangoClient, dbMongo *db.MongoProvider, logger interfaces.Logger) Worker {
+func NewWorker(classifier string, dbArango *db.ArangoClient, dbMongo *db.MongoProvider, logger interfaces.Logger) Worker {
 	return Worker{
-		dbArango: dbArango,
-		dbMongo:  dbMongo,
-		logger:   logger,
+		classifier: classifier,
+		dbArango:   dbArango,
+		dbMongo:    dbMongo,
+		logger:     logger,
 	}
 }
 
@@ -51,9 +56,11 @@ func (w *Worker) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool
 			w.logger.Write(logging.DebugLevel, "polling...")
 			records, err := w.dbMongo.QueryUnpopulated(ctx)
 			if err != nil {
+				populateErrors.Inc()
 				w.logger.Error(err.Error())
 			} else {
 				w.logger.Write(logging.DebugLevel, fmt.Sprintf("%v records found", len(records)))
+				recordsScanned.Add(float64(len(records)))
 				for _, item := range records {
 					appData := models.SampleFromMongoRecord(item)
 					// TODO: This should eventually be configurable according to the hash algorithm used by the Alvarium ecosystem.
@@ -61,18 +68,26 @@ func (w *Worker) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool
 					// SHA256 is being handled.
 					b, _ := json.Marshal(&appData)
 					key := hashprovider.DeriveHash(b)
-					score, err := w.dbArango.QueryScore(ctx, key)
+					score, err := w.dbArango.QueryScore(ctx, key, w.classifier)
 					if err != nil {
+						populateErrors.Inc()
 						w.logger.Error(err.Error())
 						continue
 					}
 					w.logger.Write(logging.DebugLevel, fmt.Sprintf("score for key %s is %v", key, score.Confidence))
 					if score.Confidence > 0 {
 						item.Confidence = score.Confidence
-						err = w.dbMongo.UpdateDocument(ctx, item)
+						spanCtx, span := tracing.Start(tracing.Extract(ctx, score.Trace), tracing.SpanPopulate, trace.WithAttributes(
+							attribute.String("alvarium.key", key),
+							attribute.Float64("alvarium.confidence", score.Confidence)))
+						err = w.dbMongo.UpdateDocument(spanCtx, item)
+						tracing.End(span, err)
 						if err != nil {
+							populateErrors.Inc()
 							w.logger.Error(err.Error())
+							continue
 						}
+						recordsUpdated.Inc()
 					}
 				}
 			}
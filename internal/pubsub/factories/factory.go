@@ -16,37 +16,70 @@ package factories
 
 import (
 	"fmt"
-	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/contracts"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
-	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/mock"
-	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/mqtt"
+	"sort"
+	"sync"
 )
 
-func NewPublisher(cfg SdkConfig.StreamInfo) (interfaces.Publisher, error) {
-	switch cfg.Type {
-	case contracts.MockStream:
-		return mock.NewMockPublisher(cfg), nil
-	case contracts.MqttStream:
-		t, ok := cfg.Config.(SdkConfig.MqttConfig)
-		if !ok {
-			return nil, fmt.Errorf("%s invalid type for EndpointInfo.Config %T", cfg.Type, cfg.Config)
-		}
-		return mqtt.NewMqttPublisher(t), nil
+// Provider creates the publishers and subscribers of a pub/sub platform. Either function may be nil if the platform
+// only works in one direction.
+type Provider struct {
+	NewPublisher  func(cfg config.StreamInfo) (interfaces.Publisher, error)
+	NewSubscriber func(cfg config.StreamInfo) (interfaces.Subscriber, error)
+}
+
+var (
+	providers = make(map[config.StreamType]Provider)
+	mutex     sync.RWMutex
+)
+
+// Register makes a pub/sub provider available by its stream type, both for the CalculateScore channel and for the
+// subscriber's annotation streams. Registering the same type twice panics.
+func Register(t config.StreamType, provider Provider) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if _, exists := providers[t]; exists {
+		panic(fmt.Sprintf("pub/sub provider %s registered twice", t))
+	}
+	providers[t] = provider
+}
+
+// Registered lists the pub/sub providers included in this build
+func Registered() []config.StreamType {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	types := make([]config.StreamType, 0, len(providers))
+	for t := range providers {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// CanSubscribe indicates whether a provider registered for t is able to subscribe
+func CanSubscribe(t config.StreamType) bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return providers[t].NewSubscriber != nil
+}
+
+func NewPublisher(cfg config.StreamInfo) (interfaces.Publisher, error) {
+	mutex.RLock()
+	provider, ok := providers[cfg.Type]
+	mutex.RUnlock()
+	if !ok || provider.NewPublisher == nil {
+		return nil, fmt.Errorf("unrecognized ProviderType: %s", cfg.Type)
 	}
-	return nil, fmt.Errorf("unrecognized ProviderType: %s", cfg.Type)
+	return provider.NewPublisher(cfg)
 }
 
-func NewSubscriber(cfg SdkConfig.StreamInfo) (interfaces.Subscriber, error) {
-	switch cfg.Type {
-	case contracts.MockStream:
-		return mock.NewMockSubscriber(cfg), nil
-	case contracts.MqttStream:
-		t, ok := cfg.Config.(SdkConfig.MqttConfig)
-		if !ok {
-			return nil, fmt.Errorf("%s invalid type for EndpointInfo.Config %T", cfg.Type, cfg.Config)
-		}
-		return mqtt.NewMqttSubscriber(t)
+func NewSubscriber(cfg config.StreamInfo) (interfaces.Subscriber, error) {
+	mutex.RLock()
+	provider, ok := providers[cfg.Type]
+	mutex.RUnlock()
+	if !ok || provider.NewSubscriber == nil {
+		return nil, fmt.Errorf("unrecognized ProviderType: %s", cfg.Type)
 	}
-	return nil, fmt.Errorf("unrecognized ProviderType: %s", cfg.Type)
+	return provider.NewSubscriber(cfg)
 }
@@ -0,0 +1,133 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package factories
+
+import (
+	"fmt"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/kafka"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/memory"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/mock"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/mqtt"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/nats"
+)
+
+func init() {
+	Register(config.MockStream, Provider{
+		NewPublisher: func(cfg config.StreamInfo) (interfaces.Publisher, error) {
+			return mock.NewMockPublisher(cfg), nil
+		},
+		NewSubscriber: func(cfg config.StreamInfo) (interfaces.Subscriber, error) {
+			return mock.NewMockSubscriber(cfg), nil
+		},
+	})
+	Register(config.MqttStream, Provider{
+		NewPublisher: func(cfg config.StreamInfo) (interfaces.Publisher, error) {
+			t, err := mqttConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return mqtt.NewMqttPublisher(t)
+		},
+		NewSubscriber: func(cfg config.StreamInfo) (interfaces.Subscriber, error) {
+			t, err := mqttConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return mqtt.NewMqttSubscriber(t)
+		},
+	})
+	Register(config.KafkaStream, Provider{
+		NewPublisher: func(cfg config.StreamInfo) (interfaces.Publisher, error) {
+			t, err := kafkaConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return kafka.NewKafkaPublisher(t)
+		},
+		NewSubscriber: func(cfg config.StreamInfo) (interfaces.Subscriber, error) {
+			t, err := kafkaConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return kafka.NewKafkaSubscriber(t)
+		},
+	})
+	Register(config.NatsStream, Provider{
+		NewPublisher: func(cfg config.StreamInfo) (interfaces.Publisher, error) {
+			t, err := natsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return nats.NewNatsPublisher(t)
+		},
+		NewSubscriber: func(cfg config.StreamInfo) (interfaces.Subscriber, error) {
+			t, err := natsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return nats.NewNatsSubscriber(t)
+		},
+	})
+	Register(config.MemoryStream, Provider{
+		NewPublisher: func(cfg config.StreamInfo) (interfaces.Publisher, error) {
+			t, err := memoryConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return memory.NewMemoryPublisher(t)
+		},
+		NewSubscriber: func(cfg config.StreamInfo) (interfaces.Subscriber, error) {
+			t, err := memoryConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return memory.NewMemorySubscriber(t)
+		},
+	})
+}
+
+func mqttConfig(cfg config.StreamInfo) (config.MqttConfig, error) {
+	t, ok := cfg.Config.(config.MqttConfig)
+	if !ok {
+		return t, fmt.Errorf("%s invalid type for EndpointInfo.Config %T", cfg.Type, cfg.Config)
+	}
+	return t, nil
+}
+
+func kafkaConfig(cfg config.StreamInfo) (config.KafkaConfig, error) {
+	t, ok := cfg.Config.(config.KafkaConfig)
+	if !ok {
+		return t, fmt.Errorf("%s invalid type for EndpointInfo.Config %T", cfg.Type, cfg.Config)
+	}
+	return t, nil
+}
+
+func natsConfig(cfg config.StreamInfo) (config.NatsConfig, error) {
+	t, ok := cfg.Config.(config.NatsConfig)
+	if !ok {
+		return t, fmt.Errorf("%s invalid type for EndpointInfo.Config %T", cfg.Type, cfg.Config)
+	}
+	return t, nil
+}
+
+func memoryConfig(cfg config.StreamInfo) (config.MemoryConfig, error) {
+	t, ok := cfg.Config.(config.MemoryConfig)
+	if !ok {
+		return t, fmt.Errorf("%s invalid type for EndpointInfo.Config %T", cfg.Type, cfg.Config)
+	}
+	return t, nil
+}
@@ -0,0 +1,27 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package interfaces
+
+// Health is implemented by publishers and subscribers that hold a connection to their provider, so its state can be
+// reported while the client recovers from a dropped connection on its own.
+type Health interface {
+	Connected() bool
+}
+
+// Reporter is implemented by publishers that raise errors outside of a call to Publish, such as a lost connection, so
+// they can be logged. Subscribers report these on the channel passed to Subscribe instead.
+type Reporter interface {
+	Report(chErrors chan<- error)
+}
@@ -19,8 +19,11 @@ import (
 	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
 )
 
-// generic subscriber interface
+// Subscriber is the contract every pub/sub provider implements, whether it carries annotations or CalculateScore keys.
+// Subscribe returns once the subscription is established, or with the error that prevented it. Messages are then
+// delivered with their payload undecoded until ctx is cancelled, after which chMessage is closed. Errors raised while
+// consuming are sent to chErrors, which must be read until Close has returned.
 type Subscriber interface {
-	Subscribe(ctx context.Context, chMessage chan<- msg.SubscribeWrapper, chErrors chan<- error)
+	Subscribe(ctx context.Context, chMessage chan<- msg.Delivery, chErrors chan<- error) error
 	Close() error
 }
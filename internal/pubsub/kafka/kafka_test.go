@@ -0,0 +1,123 @@
+//go:build integration
+// +build integration
+
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"github.com/segmentio/kafka-go"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// These tests need a broker, for example the single node started by scripts/docker/docker-compose-kafka.yml. Run them
+// with KAFKA_BROKERS=localhost:9092 go test -tags integration ./internal/pubsub/kafka/...
+func brokers(t *testing.T) []string {
+	v := os.Getenv("KAFKA_BROKERS")
+	if len(v) == 0 {
+		t.Skip("KAFKA_BROKERS is not set")
+	}
+	return strings.Split(v, ",")
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	cfg := config.KafkaConfig{
+		Brokers:  brokers(t),
+		ClientId: "integration-test",
+		GroupId:  fmt.Sprintf("group-%v", time.Now().UnixNano()),
+		Topics:   []string{fmt.Sprintf("topic-%v", time.Now().UnixNano())},
+	}
+	createTopics(t, cfg)
+
+	pub, err := NewKafkaPublisher(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer pub.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*60)
+	defer cancel()
+	err = pub.Publish(ctx, msg.PublishWrapper{MessageType: "CalculateScore", Content: []byte("first")})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := receive(ctx, t, cfg); got != "first" {
+		t.Fatalf("expected first, got %s", got)
+	}
+
+	// "first" was acknowledged, committing it for the group, so a new member of the same group should only see what follows it
+	err = pub.Publish(ctx, msg.PublishWrapper{MessageType: "CalculateScore", Content: []byte("second")})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := receive(ctx, t, cfg); got != "second" {
+		t.Fatalf("expected second, got %s", got)
+	}
+}
+
+func receive(ctx context.Context, t *testing.T, cfg config.KafkaConfig) string {
+	sub, err := NewKafkaSubscriber(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer sub.Close()
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	chMessages := make(chan msg.Delivery)
+	chErrors := make(chan error, 1)
+	if err = sub.Subscribe(subCtx, chMessages, chErrors); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	select {
+	case m := <-chMessages:
+		if err = m.Ack(); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		var wrap msg.SubscribeWrapper
+		if err = json.Unmarshal(m.Payload, &wrap); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return string(wrap.Content)
+	case err := <-chErrors:
+		t.Fatalf("unexpected error %v", err)
+	case <-ctx.Done():
+		t.Fatalf("timed out waiting for message")
+	}
+	return ""
+}
+
+func createTopics(t *testing.T, cfg config.KafkaConfig) {
+	conn, err := kafka.Dial("tcp", cfg.Brokers[0])
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer conn.Close()
+	for _, topic := range cfg.Topics {
+		err = conn.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+}
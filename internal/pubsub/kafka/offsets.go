@@ -0,0 +1,76 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package kafka
+
+import (
+	"github.com/segmentio/kafka-go"
+	"sync"
+)
+
+type partition struct {
+	topic string
+	id    int
+}
+
+type pendingOffset struct {
+	acked   bool
+	message kafka.Message
+}
+
+// offsetTracker holds back a partition's commit until every message fetched before it has been acknowledged, since
+// committing an offset implicitly acknowledges the messages preceding it. Consumers such as the calculator acknowledge
+// out of order.
+type offsetTracker struct {
+	mutex   sync.Mutex
+	pending map[partition][]*pendingOffset
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{pending: make(map[partition][]*pendingOffset)}
+}
+
+// fetched records a message in the order it was fetched from its partition
+func (t *offsetTracker) fetched(m kafka.Message) *pendingOffset {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	p := &pendingOffset{message: m}
+	key := partition{topic: m.Topic, id: m.Partition}
+	t.pending[key] = append(t.pending[key], p)
+	return p
+}
+
+// ack marks p as acknowledged and commits the latest message of its partition with nothing unacknowledged before it,
+// if that has changed. Commits are made while holding the lock so they can't reach the broker out of order.
+func (t *offsetTracker) ack(p *pendingOffset, commit func(m kafka.Message) error) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	p.acked = true
+
+	key := partition{topic: p.message.Topic, id: p.message.Partition}
+	queue := t.pending[key]
+	done := 0
+	for done < len(queue) && queue[done].acked {
+		done++
+	}
+	if done == 0 {
+		return nil
+	}
+	err := commit(queue[done-1].message)
+	if err != nil {
+		return err
+	}
+	t.pending[key] = queue[done:]
+	return nil
+}
@@ -0,0 +1,49 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package kafka
+
+import (
+	"github.com/segmentio/kafka-go"
+	"testing"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	tracker := newOffsetTracker()
+	var committed []int64
+	commit := func(m kafka.Message) error {
+		committed = append(committed, m.Offset)
+		return nil
+	}
+
+	first := tracker.fetched(kafka.Message{Topic: "keys", Partition: 0, Offset: 1})
+	second := tracker.fetched(kafka.Message{Topic: "keys", Partition: 0, Offset: 2})
+	third := tracker.fetched(kafka.Message{Topic: "keys", Partition: 0, Offset: 3})
+	other := tracker.fetched(kafka.Message{Topic: "keys", Partition: 1, Offset: 7})
+
+	tracker.ack(third, commit)
+	tracker.ack(other, commit)
+	if len(committed) != 1 || committed[0] != 7 {
+		t.Fatalf("expected only the other partition to be committed, got %v", committed)
+	}
+
+	tracker.ack(second, commit)
+	tracker.ack(first, commit)
+	if len(committed) != 2 || committed[1] != 3 {
+		t.Errorf("expected acknowledging the first message to commit through the third, got %v", committed)
+	}
+	if len(tracker.pending[partition{topic: "keys", id: 0}]) != 0 {
+		t.Errorf("expected committed messages to be released")
+	}
+}
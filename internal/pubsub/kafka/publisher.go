@@ -0,0 +1,70 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"github.com/segmentio/kafka-go"
+	"time"
+)
+
+const batchTimeout time.Duration = 10
+
+type kafkaPublisher struct {
+	endpoint config.KafkaConfig
+	writer   *kafka.Writer
+}
+
+func NewKafkaPublisher(cfg config.KafkaConfig) (interfaces.Publisher, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("at least one kafka broker should be configured")
+	}
+	if len(cfg.Topics) == 0 {
+		return nil, errors.New("at least one topic value should be configured")
+	}
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: time.Millisecond * batchTimeout, // Messages are published one at a time, don't wait to fill a batch
+		RequiredAcks: kafka.RequireOne,
+		Transport:    &kafka.Transport{ClientID: cfg.ClientId},
+	}
+	return &kafkaPublisher{
+		endpoint: cfg,
+		writer:   w,
+	}, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, message msg.PublishWrapper) error {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	// publish to all topics
+	var messages []kafka.Message
+	for _, topic := range p.endpoint.Topics {
+		messages = append(messages, kafka.Message{Topic: topic, Value: b})
+	}
+	return p.writer.WriteMessages(ctx, messages...)
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
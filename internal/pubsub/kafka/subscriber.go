@@ -0,0 +1,107 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"github.com/segmentio/kafka-go"
+	"time"
+)
+
+const dialTimeout time.Duration = 10000
+
+type kafkaSubscriber struct {
+	offsets *offsetTracker
+	reader  *kafka.Reader
+}
+
+// NewKafkaSubscriber returns a Subscriber that joins the configured consumer group. Offsets are committed as messages
+// are acknowledged, so a subscriber that stops part way through resumes from the first message it had not finished.
+func NewKafkaSubscriber(cfg config.KafkaConfig) (interfaces.Subscriber, error) {
+	r, err := newReader(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaSubscriber{
+		offsets: newOffsetTracker(),
+		reader:  r,
+	}, nil
+}
+
+// newReader creates a consumer group reader for the configured topics
+func newReader(cfg config.KafkaConfig) (*kafka.Reader, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("at least one kafka broker should be configured")
+	}
+	if len(cfg.Topics) == 0 {
+		return nil, errors.New("at least one topic value should be configured")
+	}
+	if len(cfg.GroupId) == 0 {
+		return nil, errors.New("a kafka groupId should be configured")
+	}
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.Brokers,
+		GroupID:     cfg.GroupId,
+		GroupTopics: cfg.Topics,
+		Dialer: &kafka.Dialer{
+			ClientID:  cfg.ClientId,
+			DualStack: true,
+			Timeout:   time.Millisecond * dialTimeout,
+		},
+	}), nil
+}
+
+func (s *kafkaSubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.Delivery, chErrors chan<- error) error {
+	go func() {
+		defer close(chMessage)
+		for {
+			m, err := s.reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					chErrors <- err
+				}
+				return
+			}
+
+			delivery := msg.Delivery{
+				Payload: m.Value,
+				Ack:     s.ack(s.offsets.fetched(m)),
+			}
+			select {
+			case chMessage <- delivery:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *kafkaSubscriber) Close() error {
+	return s.reader.Close()
+}
+
+// ack uses its own context since messages are still acknowledged while the caller drains during shutdown
+func (s *kafkaSubscriber) ack(p *pendingOffset) func() error {
+	return func() error {
+		return s.offsets.ack(p, func(m kafka.Message) error {
+			return s.reader.CommitMessages(context.Background(), m)
+		})
+	}
+}
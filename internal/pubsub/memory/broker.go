@@ -0,0 +1,105 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultBroker = "default"
+
+var (
+	brokers = make(map[string]*Broker)
+	mutex   sync.Mutex
+)
+
+// Broker fans messages out to every subscription on a topic. Nothing is retained, a message published while nobody is
+// subscribed to its topic is discarded.
+type Broker struct {
+	mutex  sync.RWMutex
+	topics map[string]map[*subscription]bool
+}
+
+type subscription struct {
+	ch   chan []byte
+	done chan struct{}
+}
+
+// GetBroker returns the process-wide broker with the given name, creating it on first use.
+func GetBroker(name string) *Broker {
+	if len(name) == 0 {
+		name = defaultBroker
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	b, ok := brokers[name]
+	if !ok {
+		b = &Broker{topics: make(map[string]map[*subscription]bool)}
+		brokers[name] = b
+	}
+	return b
+}
+
+// Publish delivers payload to every subscription on topic. Once a subscription's buffer is full, Publish waits for it
+// to catch up, or for ctx to be cancelled.
+func (b *Broker) Publish(ctx context.Context, topic string, payload []byte) error {
+	b.mutex.RLock()
+	subs := make([]*subscription, 0, len(b.topics[topic]))
+	for s := range b.topics[topic] {
+		subs = append(subs, s)
+	}
+	b.mutex.RUnlock()
+
+	for _, s := range subs {
+		select {
+		case s.ch <- payload:
+		case <-s.done: // unsubscribed while publishing
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *Broker) subscribe(topics []string, buffer int) *subscription {
+	s := &subscription{
+		ch:   make(chan []byte, buffer),
+		done: make(chan struct{}),
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, topic := range topics {
+		if b.topics[topic] == nil {
+			b.topics[topic] = make(map[*subscription]bool)
+		}
+		b.topics[topic][s] = true
+	}
+	return s
+}
+
+func (b *Broker) unsubscribe(s *subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for topic, subs := range b.topics {
+		if subs[s] {
+			delete(subs, s)
+			if len(subs) == 0 {
+				delete(b.topics, topic)
+			}
+		}
+	}
+	close(s.done)
+}
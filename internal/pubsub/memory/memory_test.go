@@ -0,0 +1,129 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"testing"
+	"time"
+)
+
+func subscribe(t *testing.T, ctx context.Context, cfg config.MemoryConfig) chan msg.Delivery {
+	sub, err := NewMemorySubscriber(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	chMessage := make(chan msg.Delivery)
+	err = sub.Subscribe(ctx, chMessage, make(chan error))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return chMessage
+}
+
+func receive(t *testing.T, ch chan msg.Delivery) msg.PublishWrapper {
+	select {
+	case d := <-ch:
+		var w msg.PublishWrapper
+		err := json.Unmarshal(d.Payload, &w)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return w
+	case <-time.After(time.Second * 5):
+		t.Fatalf("no message received")
+	}
+	return msg.PublishWrapper{}
+}
+
+func TestFanOut(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.MemoryConfig{Broker: t.Name(), Topics: []string{"scores"}}
+	first := subscribe(t, ctx, cfg)
+	second := subscribe(t, ctx, cfg)
+	other := subscribe(t, ctx, config.MemoryConfig{Broker: t.Name(), Topics: []string{"other"}, Buffer: 1})
+
+	pub, err := NewMemoryPublisher(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	go pub.Publish(ctx, msg.PublishWrapper{MessageType: "score"})
+
+	for _, ch := range []chan msg.Delivery{first, second} {
+		if w := receive(t, ch); w.MessageType != "score" {
+			t.Errorf("unexpected message type %s", w.MessageType)
+		}
+	}
+	select {
+	case <-other:
+		t.Errorf("message delivered to another topic")
+	case <-time.After(time.Millisecond * 100):
+	}
+}
+
+func TestBackpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.MemoryConfig{Broker: t.Name(), Topics: []string{"scores"}, Buffer: 2}
+	sub, err := NewMemorySubscriber(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// nobody reads chMessage, so one message is held by the forwarding goroutine and two are buffered
+	err = sub.Subscribe(ctx, make(chan msg.Delivery), make(chan error))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	pub, _ := NewMemoryPublisher(cfg)
+	for i := 0; i < 3; i++ {
+		err = pub.Publish(ctx, msg.PublishWrapper{})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	timeout, cancelTimeout := context.WithTimeout(ctx, time.Millisecond*100)
+	defer cancelTimeout()
+	err = pub.Publish(timeout, msg.PublishWrapper{})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected publish to block on a full buffer, got %v", err)
+	}
+
+	sub.Close()
+	err = pub.Publish(ctx, msg.PublishWrapper{})
+	if err != nil {
+		t.Errorf("unexpected error after unsubscribe %v", err)
+	}
+}
+
+func TestCancelClosesChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := subscribe(t, ctx, config.MemoryConfig{Broker: t.Name(), Topics: []string{"scores"}})
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("unexpected message")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("channel not closed")
+	}
+}
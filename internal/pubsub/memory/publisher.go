@@ -0,0 +1,58 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+)
+
+type memoryPublisher struct {
+	broker   *Broker
+	endpoint config.MemoryConfig
+}
+
+func NewMemoryPublisher(cfg config.MemoryConfig) (interfaces.Publisher, error) {
+	if len(cfg.Topics) == 0 {
+		return nil, errors.New("at least one topic value should be configured")
+	}
+	return &memoryPublisher{
+		broker:   GetBroker(cfg.Broker),
+		endpoint: cfg,
+	}, nil
+}
+
+func (p *memoryPublisher) Publish(ctx context.Context, message msg.PublishWrapper) error {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	// publish to all topics
+	for _, topic := range p.endpoint.Topics {
+		err = p.broker.Publish(ctx, topic, b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *memoryPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,76 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"sync"
+)
+
+// memorySubscriber receives every message published to its topics after Subscribe. Each subscriber has a buffer of
+// its own, so a slow subscriber holds up publishers but not the other subscribers' buffered messages.
+type memorySubscriber struct {
+	broker   *Broker
+	endpoint config.MemoryConfig
+	once     sync.Once
+	sub      *subscription
+}
+
+func NewMemorySubscriber(cfg config.MemoryConfig) (interfaces.Subscriber, error) {
+	if len(cfg.Topics) == 0 {
+		return nil, errors.New("at least one topic value should be configured")
+	}
+	if cfg.Buffer < 0 {
+		return nil, errors.New("buffer should not be negative")
+	}
+	return &memorySubscriber{
+		broker:   GetBroker(cfg.Broker),
+		endpoint: cfg,
+	}, nil
+}
+
+func (s *memorySubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.Delivery, chErrors chan<- error) error {
+	s.sub = s.broker.subscribe(s.endpoint.Topics, s.endpoint.Buffer)
+	go func() {
+		defer close(chMessage)
+		defer s.Close()
+		for {
+			select {
+			case payload := <-s.sub.ch:
+				select {
+				case chMessage <- msg.Delivery{Payload: payload}:
+				case <-ctx.Done():
+					return
+				}
+			case <-s.sub.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *memorySubscriber) Close() error {
+	if s.sub != nil {
+		s.once.Do(func() { s.broker.unsubscribe(s.sub) })
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ package mock
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
 	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
 	"time"
@@ -28,15 +29,24 @@ func NewMockSubscriber(cfg interface{}) interfaces.Subscriber {
 	return &mockSubscriber{}
 }
 
-func (s *mockSubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.SubscribeWrapper, chErrors chan<- error) {
-	for {
-		w := msg.SubscribeWrapper{}
-		w.MessageType = "TestMessage"
-		w.Content = []byte("This is a test message")
-		chMessage <- w
+func (s *mockSubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.Delivery, chErrors chan<- error) error {
+	go func() {
+		defer close(chMessage)
+		for {
+			w := msg.SubscribeWrapper{}
+			w.MessageType = "TestMessage"
+			w.Content = []byte("This is a test message")
+			b, _ := json.Marshal(w)
+			select {
+			case chMessage <- msg.Delivery{Payload: b}:
+			case <-ctx.Done():
+				return
+			}
 
-		time.Sleep(1 * time.Second)
-	}
+			time.Sleep(1 * time.Second)
+		}
+	}()
+	return nil
 }
 
 func (s *mockSubscriber) Close() error {
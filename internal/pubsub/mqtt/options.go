@@ -0,0 +1,116 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"io/ioutil"
+	"time"
+)
+
+const defaultMaxReconnectInterval int64 = 30000
+
+// ConnectionHandlers are notified as a client's connection to the broker changes. Any of them may be nil.
+type ConnectionHandlers struct {
+	OnConnect     func(client MQTT.Client) // OnConnect is called after the initial connection and every reconnection
+	OnConnectLost func(err error)          // OnConnectLost is called when an established connection drops
+}
+
+// NewClientOptions builds the paho options shared by every MQTT client in these applications. Once connected, a
+// client reconnects on its own after losing the broker, backing off exponentially up to cfg.MaxReconnectInterval.
+func NewClientOptions(cfg config.MqttConfig, handlers ConnectionHandlers) (*MQTT.ClientOptions, error) {
+	opts := MQTT.NewClientOptions()
+	opts.AddBroker(cfg.Provider.Uri())
+	opts.SetClientID(cfg.ClientId)
+	opts.SetUsername(cfg.User)
+	opts.SetPassword(cfg.Password)
+	opts.SetCleanSession(cfg.Cleanness)
+
+	maxReconnect := cfg.MaxReconnectInterval
+	if maxReconnect <= 0 {
+		maxReconnect = defaultMaxReconnectInterval
+	}
+	opts.SetAutoReconnect(true)
+	opts.SetMaxReconnectInterval(time.Millisecond * time.Duration(maxReconnect))
+	if handlers.OnConnect != nil {
+		opts.SetOnConnectHandler(handlers.OnConnect)
+	}
+	if handlers.OnConnectLost != nil {
+		opts.SetConnectionLostHandler(func(client MQTT.Client, err error) {
+			handlers.OnConnectLost(err)
+		})
+	}
+
+	secure := isTlsScheme(cfg.Provider.Protocol)
+	if !secure && cfg.Tls != (config.TlsInfo{}) {
+		return nil, fmt.Errorf("tls is configured but protocol %s does not use it, expected ssl, tls or mqtts", cfg.Provider.Protocol)
+	}
+	if secure {
+		tlsConfig, err := NewTlsConfig(cfg.Tls)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+	return opts, nil
+}
+
+// NewTlsConfig loads the CA bundle and client certificate named by info.
+func NewTlsConfig(info config.TlsInfo) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         info.ServerName,
+		InsecureSkipVerify: info.InsecureSkipVerify,
+	}
+
+	if len(info.CaFile) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := ioutil.ReadFile(info.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", info.CaFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(info.CertFile) > 0 || len(info.KeyFile) > 0 {
+		if len(info.CertFile) == 0 || len(info.KeyFile) == 0 {
+			return nil, fmt.Errorf("mutual tls requires both certFile and keyFile")
+		}
+		cert, err := tls.LoadX509KeyPair(info.CertFile, info.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func isTlsScheme(protocol string) bool {
+	switch protocol {
+	case "ssl", "tls", "mqtts", "tcps":
+		return true
+	}
+	return false
+}
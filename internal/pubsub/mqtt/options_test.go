@@ -0,0 +1,154 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestMutualTls connects through paho to a minimal broker listening on TLS that requires a client certificate.
+func TestMutualTls(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newCertificate(t, nil, nil, "test-ca", true)
+	server, serverKey := newCertificate(t, ca, caKey, "broker.local", false)
+	client, clientKey := newCertificate(t, ca, caKey, "client", false)
+	caFile := writePem(t, dir, "ca.pem", "CERTIFICATE", ca.Raw)
+	certFile := writePem(t, dir, "client.pem", "CERTIFICATE", client.Raw)
+	keyFile := writeKey(t, dir, "client-key.pem", clientKey)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{server.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer listener.Close()
+	newFakeBroker(listener)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	cfg := config.MqttConfig{
+		MqttConfig: SdkConfig.MqttConfig{
+			ClientId: "tls-test",
+			Provider: SdkConfig.ServiceInfo{Host: "127.0.0.1", Port: port, Protocol: "ssl"},
+		},
+		Tls: config.TlsInfo{CaFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "broker.local"},
+	}
+	if err = connect(cfg); err != nil {
+		t.Fatalf("expected mutual tls connection, got %v", err)
+	}
+
+	// Without a client certificate the broker should refuse the handshake
+	cfg.Tls.CertFile, cfg.Tls.KeyFile = "", ""
+	if err = connect(cfg); err == nil {
+		t.Errorf("expected connection without client certificate to fail")
+	}
+
+	// The broker's certificate doesn't name 127.0.0.1, so the server name override is what makes it verify
+	cfg.Tls = config.TlsInfo{CaFile: caFile, CertFile: certFile, KeyFile: keyFile}
+	if err = connect(cfg); err == nil {
+		t.Errorf("expected server name verification to fail")
+	}
+}
+
+func TestTlsRequiresSecureProtocol(t *testing.T) {
+	cfg := config.MqttConfig{
+		MqttConfig: SdkConfig.MqttConfig{Provider: SdkConfig.ServiceInfo{Host: "localhost", Port: 1883, Protocol: "tcp"}},
+		Tls:        config.TlsInfo{InsecureSkipVerify: true},
+	}
+	if _, err := NewClientOptions(cfg, ConnectionHandlers{}); err == nil {
+		t.Errorf("expected tls settings with a plaintext protocol to be rejected")
+	}
+}
+
+func connect(cfg config.MqttConfig) error {
+	opts, err := NewClientOptions(cfg, ConnectionHandlers{})
+	if err != nil {
+		return err
+	}
+	opts.SetConnectTimeout(time.Second * 5)
+	client := MQTT.NewClient(opts)
+	token := client.Connect()
+	token.Wait()
+	if token.Error() == nil {
+		client.Disconnect(0)
+	}
+	return token.Error()
+}
+
+func newCertificate(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, name string, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return cert, key
+}
+
+func writePem(t *testing.T, dir string, name string, kind string, der []byte) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return path
+}
+
+func writeKey(t *testing.T, dir string, name string, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return writePem(t, dir, name, "EC PRIVATE KEY", der)
+}
@@ -17,10 +17,13 @@ package mqtt
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	MQTT "github.com/eclipse/paho.mqtt.golang"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
 	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -30,47 +33,94 @@ const (
 )
 
 type mqttPublisher struct {
+	chErrors   chan<- error
+	closed     bool // closed is set by Close, after which errors are no longer reported
 	endpoint   config.MqttConfig
+	lost       int32 // lost is set while an established connection is down and paho is reconnecting
 	mqttClient MQTT.Client
+	mutex      sync.Mutex
 }
 
-func NewMqttPublisher(cfg config.MqttConfig) interfaces.Publisher {
-	opts := MQTT.NewClientOptions()
-	opts.AddBroker(cfg.Provider.Uri())
-	opts.SetClientID(cfg.ClientId)
-	opts.SetUsername(cfg.User)
-	opts.SetPassword(cfg.Password)
-	opts.SetCleanSession(cfg.Cleanness)
-
+func NewMqttPublisher(cfg config.MqttConfig) (interfaces.Publisher, error) {
 	p := mqttPublisher{
-		endpoint:   cfg,
-		mqttClient: MQTT.NewClient(opts),
+		endpoint: cfg,
+	}
+	opts, err := NewClientOptions(cfg, ConnectionHandlers{
+		OnConnect:     p.onConnect,
+		OnConnectLost: p.onConnectLost,
+	})
+	if err != nil {
+		return nil, err
 	}
+	p.mqttClient = MQTT.NewClient(opts)
 
-	return &p
+	return &p, nil
 }
 
 func (p *mqttPublisher) Publish(ctx context.Context, message msg.PublishWrapper) error {
-	// Verify connectivity first. If it's been dropped, this will attempt one reconnect before publish
+	// Connect on first use. Afterward paho reconnects on its own, so a publish during an outage times out or fails below.
 	err := p.reconnect()
 	if err != nil {
 		return err
 	}
 
-	b, _ := json.Marshal(message)
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
 	// publish to all topics
 	for _, topic := range p.endpoint.Topics {
 		token := p.mqttClient.Publish(topic, byte(p.endpoint.Qos), false, b)
-		token.WaitTimeout(time.Millisecond * publishTimeout)
+		if !token.WaitTimeout(time.Millisecond * publishTimeout) {
+			return fmt.Errorf("timed out publishing to %s", topic)
+		}
+		if token.Error() != nil {
+			return token.Error()
+		}
 	}
 	return nil
 }
 
+// Connected reports whether the client still holds its connection to the broker. The publisher only connects once
+// there is something to publish, so it is reported as connected until a connection it established is lost.
+func (p *mqttPublisher) Connected() bool {
+	return atomic.LoadInt32(&p.lost) == 0
+}
+
+// Report sets where errors raised by the connection callbacks, such as a lost connection, are sent
+func (p *mqttPublisher) Report(chErrors chan<- error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.chErrors = chErrors
+}
+
 func (p *mqttPublisher) Close() error {
+	p.mutex.Lock()
+	p.closed = true
+	p.mutex.Unlock()
 	p.mqttClient.Disconnect(waitOnClose)
 	return nil
 }
 
+func (p *mqttPublisher) onConnect(client MQTT.Client) {
+	atomic.StoreInt32(&p.lost, 0)
+}
+
+func (p *mqttPublisher) onConnectLost(err error) {
+	atomic.StoreInt32(&p.lost, 1)
+	p.report(fmt.Errorf("connection to broker lost, reconnecting: %w", err))
+}
+
+// report forwards an error raised by a connection callback, unless the publisher is closing and the caller may
+// have stopped reading errors.
+func (p *mqttPublisher) report(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.closed && p.chErrors != nil {
+		p.chErrors <- err
+	}
+}
+
 func (p *mqttPublisher) reconnect() error {
 	if !p.mqttClient.IsConnected() {
 		token := p.mqttClient.Connect()
@@ -0,0 +1,97 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"context"
+	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestPublisherReportsLostConnection drops the publisher's connection from the broker side and expects every loss to
+// be reported, and the publisher to be connected again once it has reconnected.
+func TestPublisherReportsLostConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer listener.Close()
+	broker := newFakeBroker(listener)
+
+	cfg := config.MqttConfig{
+		MqttConfig: SdkConfig.MqttConfig{
+			ClientId:  "publisher-test",
+			Cleanness: true,
+			Provider:  SdkConfig.ServiceInfo{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port, Protocol: "tcp"},
+			Topics:    []string{"alvarium-test"},
+		},
+		MaxReconnectInterval: 1000,
+	}
+	pub, err := NewMqttPublisher(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer pub.Close()
+	p := pub.(*mqttPublisher)
+	chErrors := make(chan error, 10)
+	p.Report(chErrors)
+
+	if !p.Connected() {
+		t.Errorf("expected publisher to be reported as connected before its first publish")
+	}
+	message := msg.PublishWrapper{MessageType: "CalculateScore", Content: []byte("key")}
+	if err = pub.Publish(context.Background(), message); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	first := broker.waitForConnection(t)
+	first.Close()
+	waitForError(t, chErrors)
+	if p.Connected() {
+		t.Errorf("expected publisher to report its lost connection")
+	}
+
+	second := broker.waitForConnection(t)
+	deadline := time.Now().Add(time.Second * 5)
+	for !p.Connected() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected publisher to report its restored connection")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if err = pub.Publish(context.Background(), message); err != nil {
+		t.Errorf("unexpected error publishing after reconnecting %v", err)
+	}
+
+	// With the broker gone for good the publisher is closed while it is still trying to reconnect
+	listener.Close()
+	second.Close()
+	waitForError(t, chErrors)
+	if p.Connected() {
+		t.Errorf("expected publisher to report its lost connection")
+	}
+}
+
+func waitForError(t *testing.T, chErrors <-chan error) {
+	select {
+	case <-chErrors:
+	case <-time.After(time.Second * 5):
+		t.Fatalf("expected the lost connection to be reported")
+	}
+}
@@ -16,87 +16,84 @@ package mqtt
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	MQTT "github.com/eclipse/paho.mqtt.golang"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
 	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
 	"sync"
+	"sync/atomic"
 )
 
 type mqttSubscriber struct {
-	chPub      chan<- msg.SubscribeWrapper
+	chErrors   chan<- error
+	chPub      chan<- msg.Delivery
+	closed     bool // closed is set by Close, after which errors are no longer reported
+	delivering sync.Mutex
 	endpoint   config.MqttConfig
 	mqttClient MQTT.Client
+	mutex      sync.Mutex
+	stopped    bool // stopped is set once chPub has been closed
+	subscribed int32
 }
 
 func NewMqttSubscriber(cfg config.MqttConfig) (interfaces.Subscriber, error) {
-	// create MQTT options
-	opts := MQTT.NewClientOptions()
-	opts.AddBroker(cfg.Provider.Uri())
-	opts.SetClientID(cfg.ClientId)
-	opts.SetUsername(cfg.User)
-	opts.SetPassword(cfg.Password)
-	opts.SetCleanSession(cfg.Cleanness)
-
 	var subscriber = mqttSubscriber{
-		endpoint:   cfg,
-		mqttClient: MQTT.NewClient(opts),
+		endpoint: cfg,
+	}
+	// create MQTT options
+	opts, err := NewClientOptions(cfg, ConnectionHandlers{
+		OnConnect:     subscriber.onConnect,
+		OnConnectLost: subscriber.onConnectLost,
+	})
+	if err != nil {
+		return nil, err
 	}
+	subscriber.mqttClient = MQTT.NewClient(opts)
 	// no error to report
 	return &subscriber, nil
 }
 
-func (s *mqttSubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.SubscribeWrapper, chErrors chan<- error) {
+func (s *mqttSubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.Delivery, chErrors chan<- error) error {
+	s.chPub = chMessage
+	s.chErrors = chErrors
 	err := s.reconnect()
 	if err != nil {
-		chErrors <- err
-		return
+		close(chMessage)
+		return err
 	}
 
-	s.chPub = chMessage
-	if len(s.endpoint.Topics) > 1 {
-		topicsMap := make(map[string]byte)
-		// build topic qos map
-		for _, topic := range s.endpoint.Topics {
-			topicsMap[topic] = byte(s.endpoint.Qos)
-		}
-
-		if token := s.mqttClient.SubscribeMultiple(topicsMap, s.mqttMessageHandler); token.Wait() {
-			if token.Error() != nil {
-				chErrors <- err
-				return
-			}
-		}
-	} else if len(s.endpoint.Topics) == 1 {
-		var topic = s.endpoint.Topics[0]
-		if token := s.mqttClient.Subscribe(topic, byte(s.endpoint.Qos), s.mqttMessageHandler); token.Wait() {
-			if token.Error() != nil {
-				chErrors <- err
-				return
-			}
-		}
-	} else {
-		chErrors <- errors.New("at least one topic value should be configured")
+	err = s.subscribe()
+	if err != nil {
+		close(chMessage)
+		return err
 	}
+	atomic.StoreInt32(&s.subscribed, 1)
 
-	var wg sync.WaitGroup
-	wg.Add(1)
 	go func() { // Graceful shutdown
-		defer wg.Done()
-
 		<-ctx.Done()
+		s.delivering.Lock()
+		defer s.delivering.Unlock()
+		s.stopped = true
 		close(chMessage)
 	}()
-	wg.Wait()
+	return nil
 }
 
 func (s *mqttSubscriber) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
 	s.mqttClient.Disconnect(waitOnClose)
 	return nil
 }
 
+// Connected reports whether the client currently holds a connection to the broker
+func (s *mqttSubscriber) Connected() bool {
+	return s.mqttClient.IsConnectionOpen()
+}
+
 func (s *mqttSubscriber) reconnect() error {
 	// Connect client to broker if not already connected
 	if !s.mqttClient.IsConnected() {
@@ -108,9 +105,51 @@ func (s *mqttSubscriber) reconnect() error {
 	return nil
 }
 
-// General message handing func
+func (s *mqttSubscriber) subscribe() error {
+	if len(s.endpoint.Topics) == 0 {
+		return errors.New("at least one topic value should be configured")
+	}
+	// build topic qos map
+	topicsMap := make(map[string]byte)
+	for _, topic := range s.endpoint.Topics {
+		topicsMap[topic] = byte(s.endpoint.Qos)
+	}
+	token := s.mqttClient.SubscribeMultiple(topicsMap, s.mqttMessageHandler)
+	token.Wait()
+	return token.Error()
+}
+
+// onConnect restores the subscriptions after a reconnection, since a clean session starts without any. The initial
+// connection is left to Subscribe so that a failure there is returned to the caller.
+func (s *mqttSubscriber) onConnect(client MQTT.Client) {
+	if atomic.LoadInt32(&s.subscribed) == 0 {
+		return
+	}
+	err := s.subscribe()
+	if err != nil {
+		s.report(fmt.Errorf("failed to resubscribe after reconnecting: %w", err))
+	}
+}
+
+func (s *mqttSubscriber) onConnectLost(err error) {
+	s.report(fmt.Errorf("connection to broker lost, reconnecting: %w", err))
+}
+
+// report forwards an error raised by a connection callback, unless the subscriber is closing and the caller may
+// have stopped reading errors.
+func (s *mqttSubscriber) report(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed && s.chErrors != nil {
+		s.chErrors <- err
+	}
+}
+
+// mqttMessageHandler hands the payload on unless the subscriber has stopped. Paho calls it for one message at a time.
 func (s *mqttSubscriber) mqttMessageHandler(client MQTT.Client, mqttMsg MQTT.Message) {
-	var wrap msg.SubscribeWrapper
-	json.Unmarshal(mqttMsg.Payload(), &wrap)
-	s.chPub <- wrap
+	s.delivering.Lock()
+	defer s.delivering.Unlock()
+	if !s.stopped {
+		s.chPub <- msg.Delivery{Payload: mqttMsg.Payload()}
+	}
 }
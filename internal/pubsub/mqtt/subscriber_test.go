@@ -0,0 +1,163 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package mqtt
+
+import (
+	"context"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestResubscribeAfterReconnect drops the subscriber's connection from the broker side and expects it to reconnect,
+// subscribe again and keep receiving messages.
+func TestResubscribeAfterReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer listener.Close()
+	broker := newFakeBroker(listener)
+
+	cfg := config.MqttConfig{
+		MqttConfig: SdkConfig.MqttConfig{
+			ClientId:  "reconnect-test",
+			Cleanness: true,
+			Provider:  SdkConfig.ServiceInfo{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port, Protocol: "tcp"},
+			Topics:    []string{"alvarium-test"},
+		},
+		MaxReconnectInterval: 1000,
+	}
+	sub, err := NewMqttSubscriber(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chMessages := make(chan msg.Delivery)
+	chErrors := make(chan error, 10)
+	if err = sub.Subscribe(ctx, chMessages, chErrors); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	first := broker.waitForSubscription(t)
+	first.Close()
+	second := broker.waitForSubscription(t)
+	if !sub.(*mqttSubscriber).Connected() {
+		t.Errorf("expected subscriber to report its connection")
+	}
+
+	publish := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	publish.TopicName = "alvarium-test"
+	publish.Payload = []byte(`{"messageType":"test"}`)
+	second.Write(publish)
+	select {
+	case m := <-chMessages:
+		if string(m.Payload) != string(publish.Payload) {
+			t.Errorf("unexpected payload %s", m.Payload)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("no message received after resubscribing")
+	}
+
+	select {
+	case <-chErrors:
+	default:
+		t.Errorf("expected the lost connection to be reported")
+	}
+}
+
+// fakeBroker implements just enough of MQTT 3.1.1 to accept connections, publishes and subscriptions at qos 0.
+type fakeBroker struct {
+	connected  chan *brokerConn
+	subscribed chan *brokerConn
+}
+
+type brokerConn struct {
+	net.Conn
+	mutex sync.Mutex
+}
+
+func (c *brokerConn) Write(p packets.ControlPacket) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return p.Write(c.Conn)
+}
+
+func newFakeBroker(listener net.Listener) *fakeBroker {
+	b := fakeBroker{connected: make(chan *brokerConn, 10), subscribed: make(chan *brokerConn, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(&brokerConn{Conn: conn})
+		}
+	}()
+	return &b
+}
+
+func (b *fakeBroker) serve(conn *brokerConn) {
+	defer conn.Close()
+	for {
+		p, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		switch t := p.(type) {
+		case *packets.ConnectPacket:
+			conn.Write(packets.NewControlPacket(packets.Connack))
+			b.connected <- conn
+		case *packets.SubscribePacket:
+			ack := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			ack.MessageID = t.MessageID
+			ack.ReturnCodes = make([]byte, len(t.Topics))
+			conn.Write(ack)
+			b.subscribed <- conn
+		case *packets.PingreqPacket:
+			conn.Write(packets.NewControlPacket(packets.Pingresp))
+		case *packets.DisconnectPacket:
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) waitForConnection(t *testing.T) *brokerConn {
+	select {
+	case conn := <-b.connected:
+		return conn
+	case <-time.After(time.Second * 10):
+		t.Fatalf("timed out waiting for a connection")
+	}
+	return nil
+}
+
+func (b *fakeBroker) waitForSubscription(t *testing.T) *brokerConn {
+	select {
+	case conn := <-b.subscribed:
+		return conn
+	case <-time.After(time.Second * 10):
+		t.Fatalf("timed out waiting for a subscription")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nats-io/nats-server/v2/server"
+	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"net"
+	"testing"
+	"time"
+)
+
+func runServer(t *testing.T) config.NatsConfig {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	go s.Start()
+	if !s.ReadyForConnections(time.Second * 10) {
+		t.Fatalf("embedded server not ready")
+	}
+	t.Cleanup(s.Shutdown)
+
+	port := s.Addr().(*net.TCPAddr).Port
+	return config.NatsConfig{
+		Provider: SdkConfig.ServiceInfo{Host: "127.0.0.1", Port: port, Protocol: "nats"},
+		Stream:   "scores",
+		Subjects: []string{"alvarium-calculator"},
+		Durable:  "calculator",
+		AckWait:  500,
+	}
+}
+
+func TestExplicitAck(t *testing.T) {
+	cfg := runServer(t)
+
+	pub, err := NewNatsPublisher(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer pub.Close()
+	err = pub.Publish(context.Background(), msg.PublishWrapper{MessageType: "CalculateScore", Content: []byte("key")})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Received but never acknowledged, so it should come back to the next member of the durable consumer
+	first := receive(t, cfg, time.Second*5, false)
+	if first == nil || string(first.Content) != "key" {
+		t.Fatalf("expected key, got %v", first)
+	}
+
+	second := receive(t, cfg, time.Second*5, true)
+	if second == nil || string(second.Content) != "key" {
+		t.Fatalf("expected key to be redelivered, got %v", second)
+	}
+
+	if third := receive(t, cfg, time.Second*2, false); third != nil {
+		t.Errorf("expected no redelivery after ack, got %s", third.Content)
+	}
+}
+
+// receive subscribes, returns the first message within the timeout and then closes the subscriber.
+func receive(t *testing.T, cfg config.NatsConfig, timeout time.Duration, ack bool) *msg.SubscribeWrapper {
+	sub, err := NewNatsSubscriber(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	chMessages := make(chan msg.Delivery)
+	chErrors := make(chan error, 10)
+	if err = sub.Subscribe(ctx, chMessages, chErrors); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer sub.Close()
+
+	select {
+	case m, ok := <-chMessages:
+		if ok {
+			if ack {
+				if err := m.Ack(); err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+			}
+			var wrap msg.SubscribeWrapper
+			if err := json.Unmarshal(m.Payload, &wrap); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			return &wrap
+		}
+	case err := <-chErrors:
+		t.Fatalf("unexpected error %v", err)
+	case <-ctx.Done():
+	}
+	return nil
+}
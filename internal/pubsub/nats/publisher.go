@@ -0,0 +1,63 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nats-io/nats.go"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+)
+
+type natsPublisher struct {
+	conn     *nats.Conn
+	endpoint config.NatsConfig
+	js       nats.JetStreamContext
+}
+
+func NewNatsPublisher(cfg config.NatsConfig) (interfaces.Publisher, error) {
+	nc, js, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &natsPublisher{
+		conn:     nc,
+		endpoint: cfg,
+		js:       js,
+	}, nil
+}
+
+// Publish returns once JetStream has acknowledged persisting the message on every subject.
+func (p *natsPublisher) Publish(ctx context.Context, message msg.PublishWrapper) error {
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	// publish to all subjects
+	for _, subject := range p.endpoint.Subjects {
+		_, err = p.js.Publish(subject, b, nats.Context(ctx))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *natsPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
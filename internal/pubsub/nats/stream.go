@@ -0,0 +1,55 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package nats
+
+import (
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+)
+
+// connect opens a JetStream context on the configured server, creating the stream if it doesn't already exist.
+func connect(cfg config.NatsConfig) (*nats.Conn, nats.JetStreamContext, error) {
+	if len(cfg.Stream) == 0 {
+		return nil, nil, errors.New("a JetStream stream should be configured")
+	}
+	if len(cfg.Subjects) == 0 {
+		return nil, nil, errors.New("at least one subject value should be configured")
+	}
+
+	nc, err := nats.Connect(cfg.Provider.Uri())
+	if err != nil {
+		return nil, nil, err
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+
+	_, err = js.StreamInfo(cfg.Stream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:     cfg.Stream,
+			Subjects: cfg.Subjects,
+			Storage:  nats.FileStorage,
+		})
+	}
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+	return nc, js, nil
+}
@@ -0,0 +1,113 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package nats
+
+import (
+	"context"
+	"errors"
+	"github.com/nats-io/nats.go"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"time"
+)
+
+const (
+	defaultAckWait int64         = 30000
+	fetchBatch     int           = 10
+	fetchWait      time.Duration = 1000
+)
+
+type natsSubscriber struct {
+	conn *nats.Conn
+	sub  *nats.Subscription
+}
+
+// NewNatsSubscriber binds a durable pull consumer to the stream. Messages must be acknowledged explicitly through
+// msg.Delivery.Ack, otherwise they are redelivered once AckWait has passed, including after a restart.
+func NewNatsSubscriber(cfg config.NatsConfig) (interfaces.Subscriber, error) {
+	if len(cfg.Durable) == 0 {
+		return nil, errors.New("a durable consumer name should be configured")
+	}
+	nc, js, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ackWait := cfg.AckWait
+	if ackWait <= 0 {
+		ackWait = defaultAckWait
+	}
+	opts := []nats.SubOpt{
+		nats.BindStream(cfg.Stream),
+		nats.AckExplicit(),
+		nats.AckWait(time.Millisecond * time.Duration(ackWait)),
+	}
+	if cfg.MaxDeliver > 0 {
+		opts = append(opts, nats.MaxDeliver(cfg.MaxDeliver))
+	}
+	// An empty subject consumes every subject of the bound stream
+	sub, err := js.PullSubscribe("", cfg.Durable, opts...)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return &natsSubscriber{
+		conn: nc,
+		sub:  sub,
+	}, nil
+}
+
+func (s *natsSubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.Delivery, chErrors chan<- error) error {
+	go func() {
+		defer close(chMessage)
+		for {
+			fetchCtx, cancel := context.WithTimeout(ctx, time.Millisecond*fetchWait)
+			msgs, err := s.sub.Fetch(fetchBatch, nats.Context(fetchCtx))
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, nats.ErrTimeout) {
+				chErrors <- err
+				if errors.Is(err, nats.ErrConnectionClosed) || errors.Is(err, nats.ErrBadSubscription) {
+					return
+				}
+				continue
+			}
+
+			for _, m := range msgs {
+				select {
+				case chMessage <- msg.Delivery{Payload: m.Data, Ack: ack(m)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Close disconnects without removing the durable consumer, so messages still pending are redelivered on restart.
+func (s *natsSubscriber) Close() error {
+	s.conn.Close()
+	return nil
+}
+
+func ack(m *nats.Msg) func() error {
+	return func() error {
+		return m.AckSync()
+	}
+}
@@ -16,20 +16,49 @@ package subscriber
 
 import (
 	"encoding/json"
-	sdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
+	"fmt"
 	logging "github.com/project-alvarium/provider-logging/pkg/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
 )
 
 type ApplicationConfig struct {
-	Database config.DatabaseInfo `json:"database,omitempty"`
-	Sdk      sdkConfig.SdkInfo   `json:"sdk,omitempty"`
-	Stream   config.PubSubInfo   `json:"stream,omitempty"`
-	Logging  logging.LoggingInfo `json:"logging,omitempty"`
-	Key      string              `json:"preSharedKey,omitempty"` // Key is for IOTA support, shared key. Needs to be moved into SDK IotaStreamConfig
+	Database   config.DatabaseInfo   `json:"database,omitempty"`
+	DeadLetter config.DeadLetterInfo `json:"deadLetter,omitempty"` // DeadLetter records messages that could not be ingested
+	Sdk        config.SdkInfo        `json:"sdk,omitempty"`
+	Streams    []config.SourceInfo   `json:"streams,omitempty"` // Streams are consumed together in place of the stream under Sdk
+	Stream     config.PubSubInfo     `json:"stream,omitempty"`
+	Logging    logging.LoggingInfo   `json:"logging,omitempty"`
+	Outbox     config.OutboxInfo     `json:"outbox,omitempty"`       // Outbox holds keys that are waiting to be published
+	Retry      config.RetryInfo      `json:"retry,omitempty"`        // Retry controls how ingestion of an AnnotationList is retried
+	Recording  config.RecordingInfo  `json:"recording,omitempty"`    // Recording taps the annotation stream to a file
+	Key        string                `json:"preSharedKey,omitempty"` // Key is for IOTA support, shared key. Needs to be moved into SDK IotaStreamConfig
+	Validation config.ValidationInfo `json:"validation,omitempty"`   // Validation applies to streams that don't set their own
+	Tracing    config.TracingInfo    `json:"tracing,omitempty"`
+	Metrics    config.MetricsInfo    `json:"metrics,omitempty"`
+	Health     config.HealthInfo     `json:"health,omitempty"`
 }
 
 func (a ApplicationConfig) AsString() string {
 	b, _ := json.Marshal(a)
 	return string(b)
 }
+
+// Sources returns the annotation streams to consume. Without any Streams configured, the stream under Sdk is consumed
+// alone and named after its type.
+func (a ApplicationConfig) Sources() ([]config.SourceInfo, error) {
+	if len(a.Streams) == 0 {
+		return []config.SourceInfo{{Name: string(a.Sdk.Stream.Type), Stream: a.Sdk.Stream, Validation: a.Validation}}, nil
+	}
+
+	names := make(map[string]bool)
+	for _, s := range a.Streams {
+		if len(s.Name) == 0 {
+			return nil, fmt.Errorf("stream of type %s requires a name", s.Stream.Type)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("stream name %s is not unique", s.Name)
+		}
+		names[s.Name] = true
+	}
+	return a.Streams, nil
+}
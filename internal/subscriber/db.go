@@ -17,6 +17,7 @@ package subscriber
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/arangodb/go-driver"
 	"github.com/arangodb/go-driver/http"
@@ -25,29 +26,43 @@ import (
 	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
 	"github.com/project-alvarium/provider-logging/pkg/logging"
 	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	"github.com/project-alvarium/scoring-apps-go/internal/hashprovider"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"sync"
 	"time"
 )
 
 type arangoClient struct {
-	cfg    config.ArangoConfig
-	chPub  chan string
-	chSub  chan message.SubscribeWrapper
-	client driver.Client
-	logger logInterface.Logger
+	cfg         config.ArangoConfig
+	chPub       chan ScoreRequest
+	chSub       chan Message
+	client      driver.Client
+	deadLetters deadletter.Store
+	logger      logInterface.Logger
+	retry       config.RetryInfo
+	validators  Validators
 }
 
-func NewArangoClient(sub chan message.SubscribeWrapper, pub chan string, dbConfig config.DatabaseInfo, logger logInterface.Logger) (arangoClient, error) {
+// NewArangoClient creates the client that ingests annotation messages. Messages that still fail after the configured
+// retries are written to deadLetters, which may be nil if dead-lettering is disabled.
+func NewArangoClient(sub chan Message, pub chan ScoreRequest, dbConfig config.DatabaseInfo, retry config.RetryInfo,
+	validators Validators, deadLetters deadletter.Store, logger logInterface.Logger) (arangoClient, error) {
 	cfg, ok := dbConfig.Config.(config.ArangoConfig)
 	if !ok {
 		return arangoClient{}, fmt.Errorf("invalid config type, expected %s", config.DBArango)
 	}
 	c := arangoClient{
-		cfg:    cfg,
-		chPub:  pub,
-		chSub:  sub,
-		logger: logger,
+		cfg:         cfg,
+		chPub:       pub,
+		chSub:       sub,
+		deadLetters: deadLetters,
+		logger:      logger,
+		retry:       retry,
+		validators:  validators,
 	}
 
 	conn, err := http.NewConnection(
@@ -68,6 +83,12 @@ func NewArangoClient(sub chan message.SubscribeWrapper, pub chan string, dbConfi
 	return c, nil
 }
 
+// Check reports whether the Arango server can be reached
+func (c *arangoClient) Check(ctx context.Context) error {
+	_, err := c.client.Version(ctx)
+	return err
+}
+
 func (c *arangoClient) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool {
 	err := c.initGraph(ctx)
 	if err != nil {
@@ -81,24 +102,7 @@ func (c *arangoClient) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup)
 		for {
 			item, ok := <-c.chSub
 			if ok {
-				switch item.Action {
-				case message.ActionCreate:
-					c.logger.Write(logging.DebugLevel, "handling create")
-					err = c.handleCreateTransit(ctx, item.Content)
-				case message.ActionTransit:
-					c.logger.Write(logging.DebugLevel, "handling transit")
-					err = c.handleCreateTransit(ctx, item.Content)
-				case message.ActionMutate:
-					c.logger.Write(logging.DebugLevel, "handling mutate")
-					err = c.handleMutate(ctx, item.Content)
-				default:
-					c.logger.Write(logging.DebugLevel, "unrecognized item.Action value %s", item.Action)
-					continue
-				}
-
-				if err != nil {
-					c.logger.Error(err.Error())
-				}
+				c.handle(ctx, item)
 			} else {
 				return
 			}
@@ -116,129 +120,261 @@ func (c *arangoClient) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup)
 	return true
 }
 
-func (c *arangoClient) handleMutate(ctx context.Context, content []byte) error {
-	var list sdkContract.AnnotationList
-	err := json.Unmarshal(content, &list)
+// handle ingests a message and acknowledges it to its stream once it has been ingested or dead-lettered, then reports
+// the outcome to Done. A message
+// that can never be ingested, such as a rejected list, is also acknowledged when there is no dead-letter store, since
+// it would otherwise hold back its stream, e.g. a Kafka partition's offset, for good. Any other message that was neither
+// ingested nor recorded is left unacknowledged for its stream to deliver again.
+func (c *arangoClient) handle(ctx context.Context, item Message) {
+	messagesReceived.WithLabelValues(item.Source, string(item.Action)).Inc()
+	err := c.Ingest(ctx, item)
+	handled := true
 	if err != nil {
-		return err
+		ingestErrors.WithLabelValues(string(item.Action)).Inc()
+		c.logger.Error(err.Error())
+		handled = c.deadLetter(ctx, item, err) || (c.deadLetters == nil && permanent(err))
+	}
+	if handled && item.Ack != nil {
+		item.Ack()
 	}
+	if item.Done != nil {
+		item.Done(handled)
+	}
+}
 
-	if len(list.Items) == 0 {
-		c.logger.Write(logging.DebugLevel, "items is zero-length")
+// Ingest validates a single annotation message and writes it to the graph, retrying according to the retry config.
+// It is used both by the BootstrapHandler and when re-driving dead letters.
+func (c *arangoClient) Ingest(ctx context.Context, item Message) (err error) {
+	ctx, span := tracing.Start(ctx, tracing.SpanIngest, trace.WithAttributes(
+		attribute.String("alvarium.source", item.Source),
+		attribute.String("alvarium.action", string(item.Action))))
+	defer func() { tracing.End(span, err) }()
+
+	switch item.Action {
+	case message.ActionCreate, message.ActionTransit, message.ActionMutate:
+	default:
+		c.logger.Write(logging.DebugLevel, fmt.Sprintf("unrecognized item.Action value %s", item.Action))
 		return nil
 	}
-	db, err := c.client.Database(ctx, c.cfg.DatabaseName)
+
+	list, err := c.validators.For(item.Source).Validate(item.Action, item.Content)
 	if err != nil {
 		return err
 	}
-	graph, err := db.Graph(ctx, c.cfg.GraphName)
-	if err != nil {
-		return err
+	c.logger.Write(logging.DebugLevel, "handling "+string(item.Action))
+	written := len(list.Items)
+	if item.Action == message.ActionMutate {
+		err = c.handleMutate(ctx, item.Source, list)
+		written-- // The source annotation only identifies the previous version of the data
+	} else {
+		err = c.handleCreateTransit(ctx, item.Source, list)
 	}
-	data, err := graph.VertexCollection(ctx, documents.VertexData) // Fetch the "data" collection
 	if err != nil {
 		return err
 	}
-	// Find the "Src" annotation first. That will point to the previous version of the data being mutated.
-	var dataRef string
-	for _, item := range list.Items {
-		if item.Kind == sdkContract.AnnotationSource {
-			dataRef = item.Key
-			break
-		}
+	annotationsWritten.WithLabelValues(string(item.Action)).Add(float64(written))
+	return nil
+}
+
+// AttemptsError is returned when ingestion gives up on an AnnotationList, recording how many times it was attempted.
+type AttemptsError struct {
+	Attempts int
+	Err      error
+}
+
+func (e AttemptsError) Error() string {
+	return e.Err.Error()
+}
+
+func (e AttemptsError) Unwrap() error {
+	return e.Err
+}
+
+// permanent reports whether ingestion failed for a reason that retrying or redelivering the message can't change
+func permanent(err error) bool {
+	var validationErr ValidationError
+	return errors.As(err, &validationErr)
+}
+
+// deadLetter records the message that failed ingestion, reporting whether the letter was written.
+func (c *arangoClient) deadLetter(ctx context.Context, item Message, err error) bool {
+	if c.deadLetters == nil {
+		return false
 	}
-	// This should already exist, but it will be interesting from a reporting perspective if we create it here b/c the
-	// upstream vertex will have no annotations.
-	err = c.createDataDocument(ctx, dataRef, data)
+	attempts := 1 // Rejected lists are never retried, and other failures report how often they were attempted
+	var attemptsErr AttemptsError
+	if errors.As(err, &attemptsErr) {
+		attempts = attemptsErr.Attempts
+	}
+	// ctx may have been cancelled mid-ingestion, which is exactly when the letter most needs to be kept
+	writeCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	letter := documents.NewDeadLetter(item.SubscribeWrapper, err, attempts)
+	letter.Source = item.Source
+	err = c.deadLetters.Write(writeCtx, letter)
 	if err != nil {
-		return err
+		c.logger.Error(err.Error())
+		return false
 	}
+	return true
+}
 
+func (c *arangoClient) handleMutate(ctx context.Context, source string, list sdkContract.AnnotationList) error {
 	var itemKey string
-	lineageCreated := false
-	for _, item := range list.Items {
-		if item.Kind != sdkContract.AnnotationSource {
-			if !lineageCreated {
-				// create the target vertex for new data version
-				err = c.createDataDocument(ctx, item.Key, data)
+	err := c.ingest(ctx, func(ctx context.Context, db driver.Database) error {
+		data, err := db.Collection(ctx, documents.VertexData) // Fetch the "data" collection
+		if err != nil {
+			return err
+		}
+		annotation, err := db.Collection(ctx, documents.VertexAnnotations)
+		if err != nil {
+			return err
+		}
+		// Find the "Src" annotation first. That will point to the previous version of the data being mutated.
+		var dataRef string
+		for _, item := range list.Items {
+			if item.Kind == sdkContract.AnnotationSource {
+				dataRef = item.Key
+				break
+			}
+		}
+		// This should already exist, but it will be interesting from a reporting perspective if we create it here b/c the
+		// upstream vertex will have no annotations.
+		err = c.createDataDocument(ctx, dataRef, data)
+		if err != nil {
+			return err
+		}
+
+		lineageCreated := false
+		for _, item := range list.Items {
+			if item.Kind != sdkContract.AnnotationSource {
+				if !lineageCreated {
+					// create the target vertex for new data version
+					err = c.createDataDocument(ctx, item.Key, data)
+					if err != nil {
+						return err
+					}
+					// then link them together
+					err = c.createEdge(ctx, dataRef, item.Key, documents.EdgeLineage, db)
+					if err != nil {
+						return err
+					}
+					lineageCreated = true
+				}
+
+				// With the DataDocument created, now create the annotations
+				err = c.createAnnotationDocument(ctx, item, source, annotation)
 				if err != nil {
 					return err
 				}
-				// then link them together
-				err = c.createEdge(ctx, dataRef, item.Key, documents.EdgeLineage, graph)
+				err = c.createEdge(ctx, item.Key, item.Id.String(), documents.EdgeTrust, db)
 				if err != nil {
 					return err
 				}
-				lineageCreated = true
+				itemKey = item.Key
 			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.publish(ctx, itemKey)
+	return nil
+}
 
-			// With the DataDocument created, now create the annotations
-			annotation, err := graph.VertexCollection(ctx, c.cfg.Vertexes[0])
-			if err != nil {
-				return err
-			}
-			err = c.createAnnotationDocument(ctx, item, annotation)
+func (c *arangoClient) handleCreateTransit(ctx context.Context, source string, list sdkContract.AnnotationList) error {
+	err := c.ingest(ctx, func(ctx context.Context, db driver.Database) error {
+		data, err := db.Collection(ctx, documents.VertexData) // Fetch the "data" collection
+		if err != nil {
+			return err
+		}
+		// For a create, all of the items will have the same key since they all related to the same piece of data.
+		err = c.createDataDocument(ctx, list.Items[0].Key, data)
+		if err != nil {
+			return err
+		}
+
+		// With the DataDocument created, now create the annotations
+		annotation, err := db.Collection(ctx, documents.VertexAnnotations)
+		if err != nil {
+			return err
+		}
+		for _, a := range list.Items {
+			err := c.createAnnotationDocument(ctx, a, source, annotation)
 			if err != nil {
 				return err
 			}
-			err = c.createEdge(ctx, item.Key, item.Id.String(), documents.EdgeTrust, graph)
+
+			err = c.createEdge(ctx, a.Key, a.Id.String(), documents.EdgeTrust, db)
 			if err != nil {
 				return err
 			}
-			itemKey = item.Key
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	c.chPub <- itemKey
+	c.publish(ctx, list.Items[0].Key)
 	return nil
 }
 
-func (c *arangoClient) handleCreateTransit(ctx context.Context, content []byte) error {
-	var list sdkContract.AnnotationList
-	err := json.Unmarshal(content, &list)
-	if err != nil {
-		return err
-	}
+// publish requests the key be scored as part of the ingestion's trace
+func (c *arangoClient) publish(ctx context.Context, key string) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("alvarium.key", key))
+	c.chPub <- ScoreRequest{Key: key, Trace: tracing.Inject(ctx)}
+}
 
-	if len(list.Items) == 0 {
-		c.logger.Write(logging.DebugLevel, "items is zero-length")
-		return nil
-	}
-	db, err := c.client.Database(ctx, c.cfg.DatabaseName)
-	if err != nil {
-		return err
-	}
-	graph, err := db.Graph(ctx, c.cfg.GraphName)
-	if err != nil {
-		return err
+// ingest runs the writes for a single AnnotationList inside an Arango stream transaction so the list is either
+// ingested completely or not at all. A failed attempt, including failing to reach the database, is aborted and retried
+// as a whole according to the retry config. The error returned once ingestion gives up is an AttemptsError.
+func (c *arangoClient) ingest(ctx context.Context, writes func(ctx context.Context, db driver.Database) error) error {
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		writeCtx, span := tracing.Start(ctx, tracing.SpanGraphWrite, trace.WithAttributes(attribute.Int("alvarium.attempt", attempt)))
+		db, err := c.client.Database(writeCtx, c.cfg.DatabaseName)
+		if err == nil {
+			err = c.transaction(writeCtx, db, writes)
+		}
+		tracing.End(span, err)
+		if err == nil {
+			return nil
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			return AttemptsError{Attempts: attempt, Err: err}
+		}
+		c.logger.Write(logging.DebugLevel, fmt.Sprintf("ingestion attempt %v failed, retrying: %s", attempt, err.Error()))
+		select {
+		case <-time.After(time.Millisecond * time.Duration(c.retry.Interval*int64(attempt))):
+		case <-ctx.Done():
+			return AttemptsError{Attempts: attempt, Err: err}
+		}
 	}
-	data, err := graph.VertexCollection(ctx, documents.VertexData) // Fetch the "data" collection
-	if err != nil {
-		return err
+}
+
+func (c *arangoClient) transaction(ctx context.Context, db driver.Database, writes func(ctx context.Context, db driver.Database) error) error {
+	cols := driver.TransactionCollections{
+		Write: []string{documents.VertexData, documents.VertexAnnotations, documents.EdgeTrust, documents.EdgeLineage},
 	}
-	// For a create, all of the items will have the same key since they all related to the same piece of data.
-	err = c.createDataDocument(ctx, list.Items[0].Key, data)
+	tid, err := db.BeginTransaction(ctx, cols, nil)
 	if err != nil {
 		return err
 	}
 
-	// With the DataDocument created, now create the annotations
-	annotation, err := graph.VertexCollection(ctx, documents.VertexAnnotations)
+	err = writes(driver.WithTransactionID(ctx, tid), db)
 	if err != nil {
-		return err
-	}
-	for _, a := range list.Items {
-		err := c.createAnnotationDocument(ctx, a, annotation)
-		if err != nil {
-			return err
-		}
-
-		err = c.createEdge(ctx, a.Key, a.Id.String(), documents.EdgeTrust, graph)
-		if err != nil {
-			return err
+		// The original error is the interesting one, an abort failure only means the server will time the transaction out
+		abortErr := db.AbortTransaction(ctx, tid, nil)
+		if abortErr != nil {
+			c.logger.Error(abortErr.Error())
 		}
+		return err
 	}
-	c.chPub <- list.Items[0].Key
-	return nil
+	return db.CommitTransaction(ctx, tid, nil)
 }
 
 func (c *arangoClient) initGraph(ctx context.Context) error {
@@ -298,51 +434,57 @@ func (c *arangoClient) initGraph(ctx context.Context) error {
 	return nil
 }
 
-func (c *arangoClient) createAnnotationDocument(ctx context.Context, a sdkContract.Annotation, collection driver.Collection) error {
+// createAnnotationDocument upserts the annotation keyed by its ID, so a redelivered annotation replaces itself rather
+// than failing the transaction with a unique constraint violation.
+func (c *arangoClient) createAnnotationDocument(ctx context.Context, a sdkContract.Annotation, source string,
+	collection driver.Collection) error {
 	doc := documents.NewAnnotation(a)
-	meta, err := collection.CreateDocument(ctx, doc)
+	doc.Source = source
+	meta, err := collection.CreateDocument(driver.WithOverwriteMode(ctx, driver.OverwriteModeReplace), doc)
 	if err != nil {
 		return err
 	}
 	b, _ := json.Marshal(meta)
-	c.logger.Write(logging.DebugLevel, "annotation document created: "+string(b))
+	c.logger.Write(logging.DebugLevel, "annotation document upserted: "+string(b))
 	return nil
 }
 
+// createDataDocument creates the data vertex unless it already exists, in which case the original is left untouched so
+// that its timestamp continues to reflect when the data was first seen.
 func (c *arangoClient) createDataDocument(ctx context.Context, documentKey string, collection driver.Collection) error {
-	exists, err := collection.DocumentExists(ctx, documentKey)
+	doc := documents.Data{
+		Key:       documentKey,
+		Timestamp: time.Now(),
+	}
+	meta, err := collection.CreateDocument(driver.WithOverwriteMode(ctx, driver.OverwriteModeIgnore), doc)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		doc := documents.Data{
-			Key:       documentKey,
-			Timestamp: time.Now(),
-		}
-		meta, err := collection.CreateDocument(ctx, doc)
-		if err != nil {
-			return err
-		}
-		b, _ := json.Marshal(meta)
-		c.logger.Write(logging.DebugLevel, "data document created: "+string(b))
-	}
+	b, _ := json.Marshal(meta)
+	c.logger.Write(logging.DebugLevel, "data document ensured: "+string(b))
 	return nil
 }
 
-func (c *arangoClient) createEdge(ctx context.Context, src string, target string, collectionName string, graph driver.Graph) error {
-	edge, _, err := graph.EdgeCollection(ctx, collectionName)
+// createEdge creates the edge unless it already exists. Edge keys are derived from the vertexes they connect so that
+// ingesting the same annotations twice does not duplicate them.
+func (c *arangoClient) createEdge(ctx context.Context, src string, target string, collectionName string, db driver.Database) error {
+	// Edges are written through the document API rather than the graph API so they take part in stream transactions
+	edge, err := db.Collection(ctx, collectionName)
 	if err != nil {
 		return err
 	}
+	ctx = driver.WithOverwriteMode(ctx, driver.OverwriteModeIgnore)
 	var meta driver.DocumentMeta
 	if collectionName == documents.EdgeTrust {
 		edgeDoc := documents.Trust{
+			Key:  target, // Each annotation is trusted by exactly one data vertex
 			From: fmt.Sprintf("%s/%s", documents.VertexData, src),
 			To:   fmt.Sprintf("%s/%s", documents.VertexAnnotations, target),
 		}
 		meta, err = edge.CreateDocument(ctx, edgeDoc)
 	} else if collectionName == documents.EdgeLineage {
 		edgeDoc := documents.Lineage{
+			Key:  hashprovider.DeriveHash([]byte(src + "/" + target)),
 			From: fmt.Sprintf("%s/%s", documents.VertexData, target),
 			To:   fmt.Sprintf("%s/%s", documents.VertexData, src),
 		}
@@ -352,6 +494,6 @@ func (c *arangoClient) createEdge(ctx context.Context, src string, target string
 		return err
 	}
 	b, _ := json.Marshal(meta)
-	c.logger.Write(logging.DebugLevel, "edge document created: "+string(b))
+	c.logger.Write(logging.DebugLevel, "edge document ensured: "+string(b))
 	return nil
 }
@@ -0,0 +1,134 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"testing"
+)
+
+func TestDeadLetterAttempts(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectAttempts int
+	}{
+		{"gave up after retrying", AttemptsError{Attempts: 2, Err: errors.New("connection refused")}, 2},
+		{"rejected list", ValidationError{Reasons: []string{"no items"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &testLetterStore{}
+			c := arangoClient{
+				deadLetters: store,
+				logger:      logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel}),
+				retry:       config.RetryInfo{MaxAttempts: 5},
+			}
+			if !c.deadLetter(context.Background(), Message{}, tt.err) {
+				t.Errorf("expected the letter to be reported as written")
+			}
+			if len(store.letters) != 1 || store.letters[0].Attempts != tt.expectAttempts {
+				t.Errorf("expected a letter recording %v attempts, got %+v", tt.expectAttempts, store.letters)
+			}
+		})
+	}
+}
+
+func TestDeadLetterUnavailable(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	err := errors.New("connection refused")
+
+	c := arangoClient{logger: logger}
+	if c.deadLetter(context.Background(), Message{}, err) {
+		t.Errorf("expected no letter to be written without a store")
+	}
+	c.deadLetters = &testLetterStore{err: err}
+	if c.deadLetter(context.Background(), Message{}, err) {
+		t.Errorf("expected a failed write to be reported")
+	}
+}
+
+func TestHandleAcks(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	rejected := Message{SubscribeWrapper: message.SubscribeWrapper{Action: message.ActionCreate, Content: []byte(`{"items":[]}`)}}
+	ignored := Message{SubscribeWrapper: message.SubscribeWrapper{Action: message.ActionPublish}}
+
+	tests := []struct {
+		name        string
+		deadLetters deadletter.Store
+		expectAcked []int
+		expectDone  []bool
+	}{
+		{"no dead-letter store", nil, []int{0, 1}, []bool{true, true}},
+		{"dead letter written", &testLetterStore{}, []int{0, 1}, []bool{true, true}},
+		{"dead letter failed", &testLetterStore{err: errors.New("connection refused")}, []int{1}, []bool{false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := arangoClient{
+				deadLetters: tt.deadLetters,
+				logger:      logger,
+				validators:  NewValidators(config.ValidationInfo{}, nil, logger),
+			}
+			// The rejected list is at the head of its partition, so nothing after it is committed until it is acked
+			var acked []int
+			var done []bool
+			for i, m := range []Message{rejected, ignored} {
+				offset := i
+				m.Ack = func() { acked = append(acked, offset) }
+				m.Done = func(ok bool) { done = append(done, ok) }
+				c.handle(context.Background(), m)
+			}
+			if fmt.Sprint(acked) != fmt.Sprint(tt.expectAcked) {
+				t.Errorf("expected %v to be acknowledged, got %v", tt.expectAcked, acked)
+			}
+			// Every message is reported as done, whether or not it was acknowledged
+			if fmt.Sprint(done) != fmt.Sprint(tt.expectDone) {
+				t.Errorf("expected outcomes %v, got %v", tt.expectDone, done)
+			}
+		})
+	}
+}
+
+// testLetterStore keeps dead letters in memory
+type testLetterStore struct {
+	err     error // err is returned by Write instead of keeping the letter when set
+	letters []documents.DeadLetter
+}
+
+func (s *testLetterStore) Write(ctx context.Context, letter documents.DeadLetter) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *testLetterStore) List(ctx context.Context) ([]documents.DeadLetter, error) {
+	return s.letters, nil
+}
+
+func (s *testLetterStore) Remove(ctx context.Context, key string) error {
+	return nil
+}
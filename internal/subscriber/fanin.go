@@ -0,0 +1,74 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"context"
+	"fmt"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"sync"
+)
+
+// FanIn merges several annotation streams into the single channel read by ingestion. Each stream publishes to its own
+// channel, obtained from Add, and every message is tagged with the name of the stream it came from unless the stream
+// already set a source, as a replayed recording does.
+type FanIn struct {
+	chOut  chan Message
+	inputs map[string]chan Message
+	logger logInterface.Logger
+}
+
+func NewFanIn(chOut chan Message, logger logInterface.Logger) FanIn {
+	return FanIn{
+		chOut:  chOut,
+		inputs: make(map[string]chan Message),
+		logger: logger,
+	}
+}
+
+// Add returns the channel the named stream should publish to. Streams close their channel on shutdown, and the output
+// is closed once all of them have.
+func (f *FanIn) Add(name string) chan Message {
+	ch := make(chan Message)
+	f.inputs[name] = ch
+	return ch
+}
+
+func (f *FanIn) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool {
+	var forwarding sync.WaitGroup
+	for name, ch := range f.inputs {
+		forwarding.Add(1)
+		go func(name string, ch chan Message) {
+			defer forwarding.Done()
+			for item := range ch {
+				if len(item.Source) == 0 {
+					item.Source = name
+				}
+				f.chOut <- item
+			}
+			f.logger.Write(logging.DebugLevel, fmt.Sprintf("stream %s closed", name))
+		}(name, ch)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		forwarding.Wait()
+		close(f.chOut)
+		f.logger.Write(logging.InfoLevel, "shutdown received")
+	}()
+	return true
+}
@@ -0,0 +1,55 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"context"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"sync"
+	"testing"
+)
+
+func TestFanIn(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	chOut := make(chan Message)
+	fanIn := NewFanIn(chOut, logger)
+	siteA := fanIn.Add("site-a")
+	siteB := fanIn.Add("site-b")
+
+	var wg sync.WaitGroup
+	if !fanIn.BootstrapHandler(context.Background(), &wg) {
+		t.Fatalf("fan-in failed to start")
+	}
+
+	go func() {
+		siteA <- Message{}
+		siteB <- Message{}
+		siteB <- Message{Source: "recorded"}
+		close(siteA)
+		close(siteB)
+	}()
+
+	sources := make(map[string]int)
+	for item := range chOut {
+		sources[item.Source]++
+	}
+	wg.Wait()
+
+	if sources["site-a"] != 1 || sources["site-b"] != 1 || sources["recorded"] != 1 || len(sources) != 3 {
+		t.Errorf("unexpected sources %v", sources)
+	}
+}
@@ -16,9 +16,28 @@ package subscriber
 
 import (
 	"context"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
 	"sync"
 )
 
+// Message is an annotation message received from a stream. Ack, if set, is called once the message has been ingested
+// or dead-lettered so that streams which track consumption, such as Kafka, only move past messages that were handled.
+// Done, if set, is called once ingestion has finished with the message whatever the outcome, reporting whether it was
+// acknowledged, for streams that wait on the messages they have passed on, such as IOTA.
+type Message struct {
+	message.SubscribeWrapper
+	Ack    func()
+	Done   func(acked bool)
+	Source string // Source names the stream the message was received from, see FanIn
+}
+
+// ScoreRequest is a key whose annotations have been ingested, along with the trace context of its ingestion so that
+// scoring continues the same trace.
+type ScoreRequest struct {
+	Key   string            `json:"key"`
+	Trace map[string]string `json:"trace,omitempty"`
+}
+
 type Subscriber interface {
 	Subscribe(ctx context.Context, wg *sync.WaitGroup) bool
 	Close()
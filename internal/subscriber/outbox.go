@@ -0,0 +1,266 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+const defaultOutboxCapacity = 1000
+
+// outbox is a FIFO of keys waiting to be published, each kept with its trace context. Once capacity keys are held in memory, further keys are appended to
+// the spill file and read back as the memory queue drains, so keys are still published in the order they arrived.
+type outbox struct {
+	capacity int
+	path     string
+	mutex    sync.Mutex
+	keys     []ScoreRequest
+	spilled  int           // spilled is the number of keys in the spill file
+	ready    chan struct{} // ready is signaled when a key has been added
+	space    chan struct{} // space is signaled when a key has been removed
+}
+
+// newOutbox picks up any keys left in the spill file by a previous run.
+func newOutbox(capacity int, path string) (*outbox, error) {
+	if capacity < 1 {
+		capacity = defaultOutboxCapacity
+	}
+	o := &outbox{
+		capacity: capacity,
+		path:     path,
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+	}
+	if len(path) > 0 {
+		keys, err := readSpill(path)
+		if err != nil {
+			return nil, err
+		}
+		o.spilled = len(keys)
+	}
+	o.observe()
+	return o, nil
+}
+
+// push adds a key to the outbox, reporting whether it overflowed the keys held in memory. An overflowing key is
+// spilled, or without a spill file push waits for room until ctx is done.
+func (o *outbox) push(ctx context.Context, key ScoreRequest) (bool, error) {
+	overflow := false
+	for {
+		o.mutex.Lock()
+		if o.spilled == 0 && len(o.keys) < o.capacity {
+			o.keys = append(o.keys, key)
+			o.observe()
+			o.mutex.Unlock()
+			signal(o.ready)
+			return overflow, nil
+		}
+		overflow = true
+		if len(o.path) > 0 {
+			err := appendSpill(o.path, key)
+			if err == nil {
+				o.spilled++
+			}
+			o.observe()
+			o.mutex.Unlock()
+			signal(o.ready)
+			return overflow, err
+		}
+		o.mutex.Unlock()
+
+		select {
+		case <-o.space:
+		case <-ctx.Done():
+			return overflow, ctx.Err()
+		}
+	}
+}
+
+// next returns the oldest key without removing it, waiting until there is one or ctx is done.
+func (o *outbox) next(ctx context.Context) (ScoreRequest, error) {
+	for {
+		o.mutex.Lock()
+		if len(o.keys) == 0 && o.spilled > 0 {
+			err := o.refill()
+			if err != nil {
+				o.mutex.Unlock()
+				return ScoreRequest{}, err
+			}
+		}
+		if len(o.keys) > 0 {
+			key := o.keys[0]
+			o.mutex.Unlock()
+			return key, nil
+		}
+		o.mutex.Unlock()
+
+		select {
+		case <-o.ready:
+		case <-ctx.Done():
+			return ScoreRequest{}, ctx.Err()
+		}
+	}
+}
+
+// remove drops the key last returned by next once it has been published.
+func (o *outbox) remove() {
+	o.mutex.Lock()
+	o.keys = o.keys[1:]
+	o.observe()
+	o.mutex.Unlock()
+	signal(o.space)
+}
+
+// requeue moves the key last returned by next behind every other key waiting, spilling it if there are spilled keys.
+// The key stays at the front if it can't be spilled.
+func (o *outbox) requeue() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	key := o.keys[0]
+	if o.spilled > 0 {
+		err := appendSpill(o.path, key)
+		if err != nil {
+			return err
+		}
+		o.keys = o.keys[1:]
+		o.spilled++
+		return nil
+	}
+	o.keys = append(o.keys[1:], key)
+	return nil
+}
+
+// persist moves the keys held in memory to the front of the spill file, so that they are published after a restart.
+// Without a spill file, or if it can't be written, the keys that are lost are returned.
+func (o *outbox) persist() ([]ScoreRequest, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if len(o.keys) == 0 || len(o.path) == 0 {
+		return o.keys, nil
+	}
+	spilled, err := readSpill(o.path)
+	if err != nil {
+		return o.keys, err
+	}
+	keys := append(append([]ScoreRequest{}, o.keys...), spilled...)
+	err = writeSpill(o.path, keys)
+	if err != nil {
+		return o.keys, err
+	}
+	o.keys = nil
+	o.spilled = len(keys)
+	return nil, nil
+}
+
+// refill moves up to capacity keys from the spill file to memory. The caller must hold the mutex.
+func (o *outbox) refill() error {
+	keys, err := readSpill(o.path)
+	if err != nil {
+		return err
+	}
+	n := o.capacity
+	if n > len(keys) {
+		n = len(keys)
+	}
+	err = writeSpill(o.path, keys[n:])
+	if err != nil {
+		return err
+	}
+	o.keys = append(o.keys, keys[:n]...)
+	o.spilled = len(keys) - n
+	return nil
+}
+
+// observe reports the number of keys waiting. The caller must hold the mutex.
+func (o *outbox) observe() {
+	outboxKeys.Set(float64(len(o.keys) + o.spilled))
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// The spill file holds one JSON encoded ScoreRequest per line.
+func readSpill(path string) ([]ScoreRequest, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []ScoreRequest
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var key ScoreRequest
+		err = json.Unmarshal(scanner.Bytes(), &key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+func appendSpill(path string, key ScoreRequest) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(f).Encode(key)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeSpill replaces the spill file through a temporary file, so a crash leaves either the old or the new keys.
+func writeSpill(path string, keys []ScoreRequest) error {
+	if len(keys) == 0 {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	for _, key := range keys {
+		err = encoder.Encode(key)
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
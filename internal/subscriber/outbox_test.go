@@ -0,0 +1,238 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"context"
+	"errors"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/alerting"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func drain(t *testing.T, o *outbox) []string {
+	var keys []string
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for {
+		key, err := o.next(ctx)
+		if err == context.Canceled {
+			return keys
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		keys = append(keys, key.Key)
+		o.remove()
+	}
+}
+
+func TestOutboxSpill(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, err := newOutbox(2, path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	keys := []string{"a", "b", "c", "d", "e"}
+	var overflows []bool
+	for _, key := range keys {
+		overflow, err := o.push(context.Background(), ScoreRequest{Key: key})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		overflows = append(overflows, overflow)
+	}
+	if !reflect.DeepEqual(overflows, []bool{false, false, true, true, true}) {
+		t.Errorf("unexpected overflows %v", overflows)
+	}
+	spilled, _ := readSpill(path)
+	if len(spilled) != 3 || spilled[0].Key != "c" || spilled[2].Key != "e" {
+		t.Errorf("unexpected spill file %v", spilled)
+	}
+
+	if drained := drain(t, o); !reflect.DeepEqual(drained, keys) {
+		t.Errorf("unexpected order %v", drained)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("spill file not removed once drained")
+	}
+}
+
+func TestOutboxPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, _ := newOutbox(2, path)
+	for _, key := range []string{"a", "b", "c"} {
+		o.push(context.Background(), ScoreRequest{Key: key, Trace: map[string]string{"traceparent": key}})
+	}
+	lost, err := o.persist()
+	if err != nil || len(lost) > 0 {
+		t.Fatalf("unexpected result %v %v", lost, err)
+	}
+
+	restarted, err := newOutbox(2, path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if drained := drain(t, restarted); !reflect.DeepEqual(drained, []string{"a", "b", "c"}) {
+		t.Errorf("unexpected keys after restart %v", drained)
+	}
+}
+
+func TestOutboxRequeue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	tests := []struct {
+		name     string
+		capacity int
+		path     string
+	}{
+		{"in memory", 10, ""},
+		{"spilled", 2, path},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _ := newOutbox(tt.capacity, tt.path)
+			for _, key := range []string{"a", "b", "c"} {
+				o.push(context.Background(), ScoreRequest{Key: key})
+			}
+			o.next(context.Background())
+			if err := o.requeue(); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if drained := drain(t, o); !reflect.DeepEqual(drained, []string{"b", "c", "a"}) {
+				t.Errorf("expected the key to be moved behind the others, got %v", drained)
+			}
+		})
+	}
+}
+
+type flakyPublisher struct {
+	failures int
+	keys     chan string
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, message msg.PublishWrapper) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	p.keys <- string(message.Content.([]byte))
+	return nil
+}
+
+func (p *flakyPublisher) Close() error {
+	return nil
+}
+
+func TestPublisherRetries(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	o, _ := newOutbox(10, "")
+	instance := &flakyPublisher{failures: 3, keys: make(chan string, 2)}
+	pub := Publisher{
+		chKeys:   make(chan ScoreRequest),
+		chAlerts: make(chan alerting.Alert, alertBuffer),
+		cfg:      config.OutboxInfo{Retry: config.RetryInfo{MaxAttempts: 5, Interval: 1}},
+		instance: instance,
+		logger:   logger,
+		outbox:   o,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	pub.BootstrapHandler(ctx, &wg)
+	pub.chKeys <- ScoreRequest{Key: "a"}
+	pub.chKeys <- ScoreRequest{Key: "b"}
+
+	if a, b := <-instance.keys, <-instance.keys; a != "a" || b != "b" {
+		t.Errorf("unexpected keys published %s %s", a, b)
+	}
+	cancel()
+	close(pub.chKeys)
+	wg.Wait()
+}
+
+// rejectingPublisher fails to publish the key it rejects
+type rejectingPublisher struct {
+	keys   chan string
+	reject string
+}
+
+func (p *rejectingPublisher) Publish(ctx context.Context, message msg.PublishWrapper) error {
+	key := string(message.Content.([]byte))
+	if key == p.reject {
+		return errors.New("message too large")
+	}
+	p.keys <- key
+	return nil
+}
+
+func (p *rejectingPublisher) Close() error {
+	return nil
+}
+
+// testSink records the alerts it is sent
+type testSink struct {
+	alerts chan alerting.Alert
+}
+
+func (s *testSink) Send(ctx context.Context, alert alerting.Alert) error {
+	s.alerts <- alert
+	return nil
+}
+
+func (s *testSink) Close() error {
+	return nil
+}
+
+func TestPublisherMaxAttempts(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	o, _ := newOutbox(10, "")
+	instance := &rejectingPublisher{keys: make(chan string, 2), reject: "a"}
+	sink := &testSink{alerts: make(chan alerting.Alert, alertBuffer)}
+	pub := Publisher{
+		alerts:   []alerting.Sink{sink},
+		chAlerts: make(chan alerting.Alert, alertBuffer),
+		chKeys:   make(chan ScoreRequest),
+		cfg:      config.OutboxInfo{Retry: config.RetryInfo{MaxAttempts: 2, Interval: 1}},
+		instance: instance,
+		logger:   logger,
+		outbox:   o,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	pub.BootstrapHandler(ctx, &wg)
+	pub.chKeys <- ScoreRequest{Key: "a"}
+	pub.chKeys <- ScoreRequest{Key: "b"}
+
+	// The key that can't be published doesn't hold back the one behind it
+	if b := <-instance.keys; b != "b" {
+		t.Errorf("unexpected key published %s", b)
+	}
+	if a := <-sink.alerts; a.Type != config.RuleOutbox {
+		t.Errorf("unexpected alert %+v", a)
+	}
+	cancel()
+	close(pub.chKeys)
+	wg.Wait()
+}
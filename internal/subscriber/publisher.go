@@ -16,53 +16,127 @@ package subscriber
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	SdkConfig "github.com/project-alvarium/alvarium-sdk-go/pkg/config"
 	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
 	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/alerting"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/factories"
 	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/tracing"
 	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// Publisher is used to notify downstream applications that a given data item is ready for scoring.
+const (
+	alertBuffer               int    = 10 // alertBuffer is the number of alerts that may wait for the sinks
+	defaultPublishInterval    int64  = 500
+	defaultPublishMaxAttempts int    = 10
+	publishProducer           string = "subscriber"
+)
+
+// Publisher is used to notify downstream applications that a given data item is ready for scoring. Keys wait in an
+// outbox until they have been published, so they survive the broker being unavailable for a while.
 type Publisher struct {
-	chKeys   chan string
+	alerts   []alerting.Sink
+	chAlerts chan alerting.Alert
+	chKeys   chan ScoreRequest
+	cfg      config.OutboxInfo
+	failing  int32 // failing is set while the key at the head of the outbox cannot be published
 	instance interfaces.Publisher
 	logger   logInterface.Logger
+	outbox   *outbox
 }
 
-func NewPublisher(endpoint SdkConfig.StreamInfo, chKeys chan string, logger logInterface.Logger) (Publisher, error) {
+func NewPublisher(endpoint config.StreamInfo, cfg config.OutboxInfo, chKeys chan ScoreRequest, logger logInterface.Logger) (Publisher, error) {
 	t, err := factories.NewPublisher(endpoint)
 	if err != nil {
 		return Publisher{}, err
 
 	}
+	o, err := newOutbox(cfg.Capacity, cfg.Path)
+	if err != nil {
+		return Publisher{}, err
+	}
+	var alerts []alerting.Sink
+	for _, info := range cfg.Alerts {
+		s, err := alerting.NewSink(info, logger)
+		if err != nil {
+			return Publisher{}, err
+		}
+		alerts = append(alerts, s)
+	}
+	if cfg.Retry.Interval <= 0 {
+		cfg.Retry.Interval = defaultPublishInterval
+	}
+	if cfg.Retry.MaxAttempts < 1 {
+		cfg.Retry.MaxAttempts = defaultPublishMaxAttempts
+	}
 	return Publisher{
+		alerts:   alerts,
+		chAlerts: make(chan alerting.Alert, alertBuffer),
 		chKeys:   chKeys,
+		cfg:      cfg,
 		instance: t,
 		logger:   logger,
+		outbox:   o,
 	}, nil
 }
 
 func (s *Publisher) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool {
-	wg.Add(1)
+	// Providers that hold a connection report losing it here, until they have been closed
+	chErrors := make(chan error)
 	go func() {
-		defer wg.Done()
-		for {
-			key, ok := <-s.chKeys
-			if ok {
-				toSend := msg.PublishWrapper{
-					MessageType: "CalculateScore",
-					Content:     []byte(key),
-				}
-				s.instance.Publish(ctx, toSend)
+		for err := range chErrors {
+			s.logger.Error(err.Error())
+		}
+	}()
+	if r, ok := s.instance.(interfaces.Reporter); ok {
+		r.Report(chErrors)
+	}
 
-				s.logger.Write(logging.DebugLevel, fmt.Sprintf("CalculateScore published %s", key))
-			} else {
-				return
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		overflowing := false
+		for key := range s.chKeys {
+			overflow, err := s.outbox.push(ctx, key)
+			if err != nil {
+				s.logger.Error(fmt.Sprintf("CalculateScore %s lost: %s", key.Key, err.Error()))
+				continue
+			}
+			if overflow && !overflowing {
+				message := "publish outbox is full, ingestion is waiting for keys to be published"
+				if len(s.cfg.Path) > 0 {
+					message = "publish outbox is full, keys are being spilled to " + s.cfg.Path
+				}
+				s.alert(message)
 			}
+			overflowing = overflow
+		}
+	}()
+
+	go func() {
+		defer workers.Done()
+		s.publish(ctx)
+	}()
+
+	// Alerts are sent on their own goroutine so that a slow sink doesn't hold up ingestion or publishing
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for a := range s.chAlerts {
+			s.dispatch(ctx, a)
+		}
+		for _, a := range s.alerts {
+			a.Close()
 		}
 	}()
 
@@ -70,9 +144,130 @@ func (s *Publisher) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bo
 	go func() { // Graceful shutdown
 		defer wg.Done()
 
-		<-ctx.Done()
+		// Keys still arrive until ingestion has stopped and closed chKeys
+		workers.Wait()
+		close(s.chAlerts)
+		lost, err := s.outbox.persist()
+		if err != nil {
+			s.logger.Error(err.Error())
+		}
+		if len(lost) > 0 {
+			keys := make([]string, len(lost))
+			for i, l := range lost {
+				keys[i] = l.Key
+			}
+			s.logger.Error(fmt.Sprintf("%v unpublished CalculateScore keys lost: %s", len(lost), strings.Join(keys, ",")))
+		}
 		s.instance.Close()
+		close(chErrors)
 		s.logger.Write(logging.InfoLevel, "shutdown received")
 	}()
 	return true
 }
+
+// Check reports whether keys are being published. Not every provider holds a connection, and those that do may only
+// connect once there is something to publish, so it is the outcome of the last attempt that is reported, along with
+// a connection that has been lost since.
+func (s *Publisher) Check(ctx context.Context) error {
+	if atomic.LoadInt32(&s.failing) == 1 {
+		return errors.New("CalculateScore keys cannot be published")
+	}
+	if h, ok := s.instance.(interfaces.Health); ok && !h.Connected() {
+		return errors.New("CalculateScore publisher has lost its connection")
+	}
+	return nil
+}
+
+// publish sends the keys in the outbox in order. A key is only removed once it has been published, and is retried
+// with a growing delay until then. After Retry.MaxAttempts failed attempts the key is moved behind the other keys
+// waiting, so that a key that can't be published doesn't hold them back, and the sinks are alerted.
+func (s *Publisher) publish(ctx context.Context) {
+	attempt := 0
+	skipping := false // skipping is set once a key has been moved back, until a key is published again
+	// toSend and its span are kept across attempts, so that a retried key keeps its message id
+	var toSend msg.PublishWrapper
+	var span trace.Span
+	for {
+		key, err := s.outbox.next(ctx)
+		if err == nil {
+			if toSend.Content == nil {
+				var spanCtx context.Context
+				spanCtx, span = tracing.Start(tracing.Extract(ctx, key.Trace), tracing.SpanPublish,
+					trace.WithAttributes(attribute.String("alvarium.key", key.Key)))
+				toSend = msg.NewPublishWrapper("CalculateScore", msg.ContentTypeText, publishProducer, []byte(key.Key))
+				toSend.Headers = tracing.Inject(spanCtx)
+			}
+			err = s.instance.Publish(ctx, toSend)
+			if err == nil {
+				s.outbox.remove()
+				atomic.StoreInt32(&s.failing, 0)
+				attempt = 0
+				skipping = false
+				toSend = msg.PublishWrapper{}
+				span.End()
+				s.logger.Write(logging.DebugLevel, fmt.Sprintf("CalculateScore published %s", key.Key))
+				continue
+			}
+			publishFailures.Inc()
+			atomic.StoreInt32(&s.failing, 1)
+			span.AddEvent(err.Error())
+			err = fmt.Errorf("failed to publish CalculateScore %s: %s", key.Key, err.Error())
+		}
+		if ctx.Err() != nil {
+			if span != nil && toSend.Content != nil {
+				tracing.End(span, ctx.Err())
+			}
+			return
+		}
+
+		s.logger.Error(err.Error())
+		if attempt < s.cfg.Retry.MaxAttempts {
+			attempt++
+		}
+		if toSend.Content != nil && attempt >= s.cfg.Retry.MaxAttempts {
+			err = s.outbox.requeue()
+			if err == nil {
+				tracing.End(span, fmt.Errorf("gave up after %v attempts", attempt))
+				toSend = msg.PublishWrapper{}
+				attempt = 0
+				if !skipping {
+					skipping = true
+					s.alert(fmt.Sprintf("CalculateScore %s could not be published after %v attempts, it was moved behind the keys waiting",
+						key.Key, s.cfg.Retry.MaxAttempts))
+				}
+				continue
+			}
+			s.logger.Error(err.Error())
+		}
+		select {
+		case <-time.After(time.Millisecond * time.Duration(s.cfg.Retry.Interval*int64(attempt))):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// alert queues a message for the configured sinks. The alert is dropped if the queue is full.
+func (s *Publisher) alert(message string) {
+	s.logger.Error(message)
+	a := alerting.Alert{
+		Rule:      string(config.RuleOutbox),
+		Type:      config.RuleOutbox,
+		Subject:   "CalculateScore",
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+	select {
+	case s.chAlerts <- a:
+	default:
+		s.logger.Error("outbox alert queue is full, dropped alert")
+	}
+}
+
+func (s *Publisher) dispatch(ctx context.Context, a alerting.Alert) {
+	for _, sink := range s.alerts {
+		err := sink.Send(ctx, a)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("outbox alert failed: %s", err.Error()))
+		}
+	}
+}
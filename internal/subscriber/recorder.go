@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record is a line of a recording. It is what the Recorder writes and what a stream of type "file" replays.
+type Record struct {
+	ReceivedAt time.Time                `json:"receivedAt,omitempty"` // ReceivedAt is when the subscriber received the message
+	Source     string                   `json:"source,omitempty"`     // Source names the stream the message was received from
+	Message    message.SubscribeWrapper `json:"message,omitempty"`
+}
+
+// Recorder sits between the annotation stream and ingestion, appending each message to the recording before passing
+// it on unchanged.
+type Recorder struct {
+	chIn   chan Message
+	chOut  chan Message
+	logger logInterface.Logger
+	path   string
+}
+
+func NewRecorder(path string, chIn chan Message, chOut chan Message, logger logInterface.Logger) Recorder {
+	return Recorder{
+		chIn:   chIn,
+		chOut:  chOut,
+		logger: logger,
+		path:   path,
+	}
+}
+
+func (r *Recorder) BootstrapHandler(ctx context.Context, wg *sync.WaitGroup) bool {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		r.logger.Error(err.Error())
+		return false
+	}
+	r.logger.Write(logging.InfoLevel, "recording annotation stream to "+r.path)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer f.Close()
+
+		encoder := json.NewEncoder(f)
+		for {
+			item, ok := <-r.chIn
+			if !ok {
+				close(r.chOut)
+				r.logger.Write(logging.InfoLevel, "shutdown received")
+				return
+			}
+			// A recording that can't be written shouldn't hold up ingestion
+			err := encoder.Encode(Record{ReceivedAt: time.Now(), Source: item.Source, Message: item.SubscribeWrapper})
+			if err != nil {
+				r.logger.Error(err.Error())
+			}
+			r.chOut <- item
+		}
+	}()
+	return true
+}
@@ -0,0 +1,148 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package streams
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	pubsub "github.com/project-alvarium/scoring-apps-go/internal/pubsub/factories"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"sync"
+)
+
+// brokerSubscriber consumes annotations through any provider in the pub/sub registry, the same providers that carry
+// CalculateScore keys. Payloads are decoded as SDK SubscribeWrappers, and each message is acknowledged to its provider
+// once it has been ingested or dead-lettered.
+type brokerSubscriber struct {
+	chPub       chan subscriber.Message
+	deadLetters deadletter.Store
+	instance    interfaces.Subscriber
+	logger      logInterface.Logger
+}
+
+func newBrokerSubscriber(cfg config.StreamInfo, pub chan subscriber.Message, deadLetters deadletter.Store,
+	logger logInterface.Logger) (subscriber.Subscriber, error) {
+	instance, err := pubsub.NewSubscriber(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &brokerSubscriber{
+		chPub:       pub,
+		deadLetters: deadLetters,
+		instance:    instance,
+		logger:      logger,
+	}, nil
+}
+
+func (s *brokerSubscriber) Subscribe(ctx context.Context, wg *sync.WaitGroup) bool {
+	chErrors := make(chan error)
+	go func() {
+		for err := range chErrors {
+			s.logger.Error(err.Error())
+		}
+	}()
+
+	chDeliveries := make(chan msg.Delivery)
+	err := s.instance.Subscribe(ctx, chDeliveries, chErrors)
+	if err != nil {
+		s.logger.Error(err.Error())
+		close(chErrors)
+		return false
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(s.chPub)
+		for d := range chDeliveries {
+			m, ok := s.decode(d)
+			if ok {
+				s.chPub <- m
+			}
+		}
+		// The provider only stops delivering before shutdown if it can't continue
+		bootstrap.Fail(ctx, errors.New("deliveries stopped before shutdown"))
+	}()
+
+	wg.Add(1)
+	go func() { // Graceful shutdown
+		defer wg.Done()
+
+		<-ctx.Done()
+		s.Close()
+		close(chErrors)
+		s.logger.Write(logging.InfoLevel, "shutdown received")
+	}()
+	return true
+}
+
+func (s *brokerSubscriber) Close() {
+	err := s.instance.Close()
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// Connected reports the provider's connection state, for providers that track one
+func (s *brokerSubscriber) Connected() bool {
+	if h, ok := s.instance.(interfaces.Health); ok {
+		return h.Connected()
+	}
+	return true
+}
+
+// decode unmarshals a delivery into the message passed to ingestion. A payload that isn't a SubscribeWrapper will
+// never succeed, so it is dead-lettered and acknowledged, or just acknowledged when there is no dead-letter store so
+// that it doesn't hold back the stream. If the letter can't be written the delivery is left unacknowledged for the
+// broker to deliver again.
+func (s *brokerSubscriber) decode(d msg.Delivery) (subscriber.Message, bool) {
+	ack := func() {
+		if d.Ack == nil {
+			return
+		}
+		err := d.Ack()
+		if err != nil {
+			s.logger.Error(err.Error())
+		}
+	}
+
+	var wrapped message.SubscribeWrapper
+	err := json.Unmarshal(d.Payload, &wrapped)
+	if err != nil {
+		s.logger.Error(err.Error())
+		if s.deadLetters == nil {
+			ack()
+			return subscriber.Message{}, false
+		}
+		err = s.deadLetters.Write(context.Background(), documents.NewRawDeadLetter(d.Payload, err))
+		if err != nil {
+			s.logger.Error(err.Error())
+		} else {
+			ack()
+		}
+		return subscriber.Message{}, false
+	}
+	return subscriber.Message{SubscribeWrapper: wrapped, Ack: ack}, true
+}
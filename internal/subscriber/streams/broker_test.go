@@ -0,0 +1,128 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package streams
+
+import (
+	"context"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	pubsub "github.com/project-alvarium/scoring-apps-go/internal/pubsub/factories"
+	"github.com/project-alvarium/scoring-apps-go/internal/pubsub/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+	"github.com/project-alvarium/scoring-apps-go/pkg/msg"
+	"sync"
+	"testing"
+)
+
+const fakeBroker config.StreamType = "fake-broker"
+
+var fakeAcks chan string
+
+func init() {
+	pubsub.Register(fakeBroker, pubsub.Provider{
+		NewSubscriber: func(cfg config.StreamInfo) (interfaces.Subscriber, error) {
+			return &fakeSubscriber{acked: fakeAcks}, nil
+		},
+	})
+}
+
+// TestBrokerSubscriber registers a pub/sub provider and expects the annotation stream to consume through it, passing
+// acknowledgements back and dead-lettering payloads that aren't messages.
+func TestBrokerSubscriber(t *testing.T) {
+	acked := make(chan string, 2)
+	fakeAcks = acked
+
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	letters := &fakeStore{}
+	chMessages := make(chan subscriber.Message)
+	sub, err := NewSubscriber(config.StreamInfo{Type: fakeBroker}, chMessages, "", letters, logger)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	if !sub.Subscribe(ctx, &wg) {
+		t.Fatalf("subscriber failed to start")
+	}
+
+	m := <-chMessages
+	if m.Action != message.ActionCreate {
+		t.Errorf("unexpected action %s", m.Action)
+	}
+	m.Ack()
+	cancel()
+	for range chMessages {
+	}
+	wg.Wait()
+
+	close(acked)
+	var order []string
+	for a := range acked {
+		order = append(order, a)
+	}
+	if len(order) != 2 || order[0] != "bad" || order[1] != "good" {
+		t.Errorf("expected both deliveries to be acknowledged, got %v", order)
+	}
+	if len(letters.letters) != 1 || string(letters.letters[0].Raw) != "not json" {
+		t.Errorf("expected the bad payload to be dead-lettered, got %v", letters.letters)
+	}
+}
+
+type fakeSubscriber struct {
+	acked chan string
+}
+
+func (s *fakeSubscriber) Subscribe(ctx context.Context, chMessage chan<- msg.Delivery, chErrors chan<- error) error {
+	go func() {
+		defer close(chMessage)
+		chMessage <- msg.Delivery{Payload: []byte("not json"), Ack: s.ack("bad")}
+		chMessage <- msg.Delivery{Payload: []byte(`{"action":"create","messageType":"test"}`), Ack: s.ack("good")}
+		<-ctx.Done()
+	}()
+	return nil
+}
+
+func (s *fakeSubscriber) Close() error {
+	return nil
+}
+
+func (s *fakeSubscriber) ack(name string) func() error {
+	return func() error {
+		s.acked <- name
+		return nil
+	}
+}
+
+type fakeStore struct {
+	letters []documents.DeadLetter
+}
+
+func (s *fakeStore) Write(ctx context.Context, letter documents.DeadLetter) error {
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *fakeStore) List(ctx context.Context) ([]documents.DeadLetter, error) {
+	return s.letters, nil
+}
+
+func (s *fakeStore) Remove(ctx context.Context, key string) error {
+	return nil
+}
@@ -15,35 +15,72 @@
 package streams
 
 import (
-	"errors"
 	"fmt"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/config"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/contracts"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
 	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	pubsub "github.com/project-alvarium/scoring-apps-go/internal/pubsub/factories"
 	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
-	"github.com/project-alvarium/scoring-apps-go/internal/subscriber/streams/iota"
-	"github.com/project-alvarium/scoring-apps-go/internal/subscriber/streams/mqtt"
+	"sort"
+	"sync"
 )
 
-func NewSubscriber(cfg config.StreamInfo, pub chan message.SubscribeWrapper, key string, logger logInterface.Logger) (subscriber.Subscriber, error) {
-	var sub subscriber.Subscriber
+// Factory creates the subscriber for a stream provider. Payloads that can't be unmarshaled are written to
+// deadLetters, which may be nil if dead-lettering is disabled.
+type Factory func(cfg config.StreamInfo, pub chan subscriber.Message, key string, deadLetters deadletter.Store,
+	logger logInterface.Logger) (subscriber.Subscriber, error)
 
-	switch cfg.Type {
-	case contracts.IotaStream:
-		endpoint, ok := cfg.Config.(config.IotaStreamConfig)
-		if !ok {
-			return nil, errors.New("unknown type cast to IotaStreamConfig failed")
+var (
+	factories = make(map[config.StreamType]Factory)
+	mutex     sync.RWMutex
+)
+
+// Register makes a stream provider available to NewSubscriber. These are sources that produce annotations directly,
+// such as the webhook. Brokers are registered once with the pub/sub factories instead, and are available here through
+// the same config. Providers register themselves from an init function, so those compiled out by build tags are simply
+// absent. Registering the same type twice panics.
+func Register(t config.StreamType, factory Factory) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if _, exists := factories[t]; exists {
+		panic(fmt.Sprintf("stream provider %s registered twice", t))
+	}
+	factories[t] = factory
+}
+
+// Registered lists the stream providers included in this build, including the pub/sub providers able to subscribe
+func Registered() []config.StreamType {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	types := make([]config.StreamType, 0, len(factories))
+	for t := range factories {
+		types = append(types, t)
+	}
+	for _, t := range pubsub.Registered() {
+		if _, exists := factories[t]; !exists && pubsub.CanSubscribe(t) {
+			types = append(types, t)
 		}
-		sub = iota.NewIotaSubscriber(endpoint, pub, logger, key)
-	case contracts.MqttStream:
-		endpoint, ok := cfg.Config.(config.MqttConfig)
-		if !ok {
-			return nil, errors.New("unknown type cast to MqttConfig failed")
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// NewSubscriber creates the stream subscriber for cfg using the provider registered for its type, falling back to the
+// pub/sub providers.
+func NewSubscriber(cfg config.StreamInfo, pub chan subscriber.Message, key string, deadLetters deadletter.Store,
+	logger logInterface.Logger) (subscriber.Subscriber, error) {
+	mutex.RLock()
+	factory, ok := factories[cfg.Type]
+	mutex.RUnlock()
+	if !ok && pubsub.CanSubscribe(cfg.Type) {
+		return newBrokerSubscriber(cfg, pub, deadLetters, logger)
+	}
+	if !ok {
+		if cfg.Type.Validate() {
+			return nil, fmt.Errorf("stream provider %s is not included in this build, available providers are %v",
+				cfg.Type, Registered())
 		}
-		sub = mqtt.NewMqttSubscriber(endpoint, pub, logger)
-	default:
-		return nil, errors.New(fmt.Sprintf("unrecognized stream provider type %s", cfg.Type))
+		return nil, fmt.Errorf("unrecognized stream provider type %s", cfg.Type)
 	}
-	return sub, nil
+	return factory(cfg, pub, key, deadLetters, logger)
 }
@@ -0,0 +1,42 @@
+//go:build !iota
+// +build !iota
+
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package streams
+
+import (
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"strings"
+	"testing"
+)
+
+func TestIotaExcludedByDefault(t *testing.T) {
+	for _, registered := range Registered() {
+		if registered == config.IotaStream {
+			t.Fatalf("iota should only be registered when building with the iota tag")
+		}
+	}
+
+	_, err := NewSubscriber(config.StreamInfo{Type: config.IotaStream}, nil, "", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "not included in this build") {
+		t.Errorf("expected iota to be reported as excluded from the build, got %v", err)
+	}
+
+	_, err = NewSubscriber(config.StreamInfo{Type: "carrier-pigeon"}, nil, "", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "unrecognized") {
+		t.Errorf("expected unknown provider to be unrecognized, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"os"
+	"sync"
+	"time"
+)
+
+const maxLineBytes int = 16 * 1024 * 1024
+
+// fileSubscriber replays a recording made by subscriber.Recorder. Messages are spaced out as they were received,
+// divided by the configured speed, so a recording can rebuild a graph quickly or reproduce the original load.
+type fileSubscriber struct {
+	chPub    chan subscriber.Message
+	endpoint config.FileStreamConfig
+	logger   logInterface.Logger
+}
+
+func NewFileSubscriber(endpoint config.FileStreamConfig, pub chan subscriber.Message, logger logInterface.Logger) subscriber.Subscriber {
+	if endpoint.Speed <= 0 {
+		endpoint.Speed = 1
+	}
+	return &fileSubscriber{
+		chPub:    pub,
+		endpoint: endpoint,
+		logger:   logger,
+	}
+}
+
+func (s *fileSubscriber) Subscribe(ctx context.Context, wg *sync.WaitGroup) bool {
+	f, err := os.Open(s.endpoint.Path)
+	if err != nil {
+		s.logger.Error(err.Error())
+		return false
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(s.chPub)
+		defer f.Close()
+
+		count, err := s.replay(ctx, f)
+		if err != nil {
+			s.logger.Error(err.Error())
+		}
+		if ctx.Err() == nil {
+			s.logger.Write(logging.InfoLevel, fmt.Sprintf("replayed %v messages from %s", count, s.endpoint.Path))
+			<-ctx.Done()
+		}
+		s.logger.Write(logging.InfoLevel, "shutdown received")
+	}()
+	return true
+}
+
+func (s *fileSubscriber) Close() {}
+
+func (s *fileSubscriber) replay(ctx context.Context, f *os.File) (int, error) {
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	count := 0
+	var previous time.Time
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record subscriber.Record
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return count, err
+		}
+
+		if !previous.IsZero() && record.ReceivedAt.After(previous) {
+			delay := time.Duration(float64(record.ReceivedAt.Sub(previous)) / s.endpoint.Speed)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return count, nil
+			}
+		}
+		previous = record.ReceivedAt
+
+		select {
+		case s.chPub <- subscriber.Message{SubscribeWrapper: record.Message, Source: record.Source}:
+			count++
+		case <-ctx.Done():
+			return count, nil
+		}
+	}
+	return count, scanner.Err()
+}
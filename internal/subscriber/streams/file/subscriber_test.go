@@ -0,0 +1,73 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package file
+
+import (
+	"context"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	actions := []message.SdkAction{message.ActionCreate, message.ActionMutate, message.ActionTransit}
+
+	// Record a few messages as they pass through to ingestion
+	chIn := make(chan subscriber.Message)
+	chOut := make(chan subscriber.Message)
+	recorder := subscriber.NewRecorder(path, chIn, chOut, logger)
+	var wg sync.WaitGroup
+	if !recorder.BootstrapHandler(context.Background(), &wg) {
+		t.Fatalf("recorder failed to start")
+	}
+	for _, a := range actions {
+		chIn <- subscriber.Message{SubscribeWrapper: message.SubscribeWrapper{Action: a, Content: []byte(a)}}
+		if out := <-chOut; out.Action != a {
+			t.Fatalf("expected %s to be passed on, got %s", a, out.Action)
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	close(chIn)
+	wg.Wait()
+
+	// Replaying at 10x should keep the order while taking a fraction of the recorded 200ms
+	ctx, cancel := context.WithCancel(context.Background())
+	chReplay := make(chan subscriber.Message)
+	sub := NewFileSubscriber(config.FileStreamConfig{Path: path, Speed: 10}, chReplay, logger)
+	if !sub.Subscribe(ctx, &wg) {
+		t.Fatalf("replay failed to start")
+	}
+	start := time.Now()
+	for _, a := range actions {
+		m := <-chReplay
+		if m.Action != a || string(m.Content) != string(a) {
+			t.Fatalf("expected %s, got %s", a, m.Action)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*150 {
+		t.Errorf("replay took %v, expected it to be accelerated", elapsed)
+	}
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,43 @@
+//go:build iota
+// +build iota
+
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package streams
+
+import (
+	"errors"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber/streams/iota"
+)
+
+func init() {
+	Register(config.IotaStream, newIotaSubscriber)
+}
+
+func newIotaSubscriber(cfg config.StreamInfo, pub chan subscriber.Message, key string, deadLetters deadletter.Store,
+	logger logInterface.Logger) (subscriber.Subscriber, error) {
+	endpoint, ok := cfg.Config.(config.IotaConfig)
+	if !ok {
+		return nil, errors.New("unknown type cast to IotaConfig failed")
+	}
+	if len(endpoint.PreSharedKey) > 0 {
+		key = endpoint.PreSharedKey
+	}
+	return iota.NewIotaSubscriber(endpoint, pub, deadLetters, logger, key)
+}
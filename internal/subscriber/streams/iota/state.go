@@ -0,0 +1,113 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package iota
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// For randomized seed generation
+const (
+	letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	seedLength  = 64
+)
+
+// State is what the subscriber needs to resume after a restart without subscribing to the author again. The seed
+// determines the subscriber's keys, so it must be kept private along with the rest of the file.
+type State struct {
+	Seed             string    `json:"seed"`
+	PskId            string    `json:"pskId,omitempty"`            // PskId identifies the pre-shared key stored with the subscriber
+	AnnouncementId   string    `json:"announcementId,omitempty"`   // AnnouncementId is the channel announcement the subscriber joined
+	SubscriptionLink string    `json:"subscriptionLink,omitempty"` // SubscriptionLink is the subscribe message sent to the author
+	LastLink         string    `json:"lastLink,omitempty"`         // LastLink is the last message the subscriber synced to
+	Exported         []byte    `json:"exported,omitempty"`         // Exported is the Streams user state, encrypted with the pre-shared key
+	Updated          time.Time `json:"updated,omitempty"`
+}
+
+// Subscribed indicates whether the state records a completed subscription handshake to resume from
+func (s State) Subscribed() bool {
+	return len(s.AnnouncementId) > 0 && len(s.SubscriptionLink) > 0
+}
+
+// LoadState reads the state at path. A file that doesn't exist yet returns a new State with a random seed.
+func LoadState(path string) (State, error) {
+	b, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		seed, err := newSeed()
+		if err != nil {
+			return State{}, err
+		}
+		return State{Seed: seed}, nil
+	} else if err != nil {
+		return State{}, err
+	}
+
+	var state State
+	if err = json.Unmarshal(b, &state); err != nil {
+		return State{}, err
+	}
+	if len(state.Seed) == 0 {
+		return State{}, errors.New("subscriber state " + path + " has no seed")
+	}
+	return state, nil
+}
+
+// SaveState replaces the state at path. The file is written alongside and renamed into place so that a crash never
+// leaves it truncated.
+func SaveState(path string, state State) error {
+	state.Updated = time.Now().UTC()
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func newSeed() (string, error) {
+	b := make([]byte, seedLength)
+	max := big.NewInt(int64(len(letterBytes)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = letterBytes[n.Int64()]
+	}
+	return string(b), nil
+}
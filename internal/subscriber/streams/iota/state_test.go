@@ -0,0 +1,69 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package iota
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "iota-state.json")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(state.Seed) != seedLength || state.Subscribed() {
+		t.Fatalf("expected a new unsubscribed state with a seed, got %+v", state)
+	}
+
+	state.PskId = "psk"
+	state.AnnouncementId = "channel:announcement"
+	state.SubscriptionLink = "channel:subscription"
+	state.LastLink = "channel:last"
+	state.Exported = []byte{0, 1, 2}
+	if err = SaveState(path, state); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	resumed, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if resumed.Seed != state.Seed || resumed.LastLink != state.LastLink || string(resumed.Exported) != string(state.Exported) {
+		t.Errorf("state did not round trip, got %+v", resumed)
+	}
+	if !resumed.Subscribed() {
+		t.Errorf("expected resumed state to be subscribed")
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected only the state file to remain, found %d files", len(files))
+	}
+}
+
+func TestStateWithoutSeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iota-state.json")
+	if err := ioutil.WriteFile(path, []byte(`{"pskId":"psk"}`), 0600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := LoadState(path); err == nil {
+		t.Errorf("expected state without a seed to be rejected")
+	}
+}
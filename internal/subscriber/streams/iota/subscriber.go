@@ -1,3 +1,6 @@
+//go:build iota
+// +build iota
+
 /*******************************************************************************
  * Copyright 2022 Dell Inc.
  *
@@ -26,15 +29,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/project-alvarium/alvarium-sdk-go/pkg/config"
 	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
 	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
 	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
 	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 )
@@ -48,36 +53,56 @@ import (
 	I used the Iota Publisher inside the SDK and also the RustAuthorConsole as examples informing this work.
 */
 
-// For randomized seed generation
-const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 const payloadLength = 1024
 
+// payload is a message read from the channel. done is called once the message has been handled, reporting whether it
+// was ingested or dead-lettered.
+type payload struct {
+	data []byte
+	done func(ok bool)
+}
+
 type iotaSubscriber struct {
-	cfg        config.IotaStreamConfig
-	chPub      chan message.SubscribeWrapper
-	logger     logInterface.Logger
-	keyload    *C.message_links_t // The Keyload indicates a key needed by the publisher to send messages to the stream
-	subscriber *C.subscriber_t    // The publisher is actually subscribed to the stream
-	seed       string
-	key        string
+	cfg         config.IotaConfig
+	chPub       chan subscriber.Message
+	deadLetters deadletter.Store
+	logger      logInterface.Logger
+	keyload     *C.message_links_t // The Keyload indicates a key needed by the publisher to send messages to the stream
+	subscriber  *C.subscriber_t    // The publisher is actually subscribed to the stream
+	publicKey   *C.public_key_t
+	state       State
+	key         string
+	stalled     bool // stalled is set once a message couldn't be handled, after which the position is no longer persisted
 }
 
-func NewIotaSubscriber(cfg config.IotaStreamConfig, pub chan message.SubscribeWrapper, logger logInterface.Logger, key string) subscriber.Subscriber {
-	bytes := make([]byte, 64)
-	rand.Seed(time.Now().UnixNano())
-	for i := range bytes {
-		bytes[i] = letterBytes[rand.Intn(len(letterBytes))]
+// NewIotaSubscriber creates the IOTA Streams subscriber. When cfg.StatePath is set, the identity and sync position
+// stored there are reused, so a restarted subscriber continues reading where it left off.
+func NewIotaSubscriber(cfg config.IotaConfig, pub chan subscriber.Message, deadLetters deadletter.Store,
+	logger logInterface.Logger, key string) (subscriber.Subscriber, error) {
+	var state State
+	var err error
+	if len(cfg.StatePath) > 0 {
+		state, err = LoadState(cfg.StatePath)
+	} else {
+		state.Seed, err = newSeed()
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	seed := string(bytes)
-	logger.Write(logging.DebugLevel, fmt.Sprintf("generated streams seed %s", seed))
-	return &iotaSubscriber{
-		cfg:    cfg,
-		chPub:  pub,
-		logger: logger,
-		seed:   seed,
-		key:    key,
+	if state.Subscribed() {
+		logger.Write(logging.DebugLevel, fmt.Sprintf("loaded streams state, last link %s", state.LastLink))
+	} else {
+		logger.Write(logging.DebugLevel, "generated streams seed")
 	}
+	return &iotaSubscriber{
+		cfg:         cfg,
+		chPub:       pub,
+		deadLetters: deadLetters,
+		logger:      logger,
+		state:       state,
+		key:         key,
+	}, nil
 }
 
 func (s *iotaSubscriber) Subscribe(ctx context.Context, wg *sync.WaitGroup) bool {
@@ -87,15 +112,15 @@ func (s *iotaSubscriber) Subscribe(ctx context.Context, wg *sync.WaitGroup) bool
 		return false
 	}
 
-	cancelled := false
-	chRawOut := make(chan []byte)
+	chRawOut := make(chan payload)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		defer close(chRawOut)
 
-		for !cancelled {
+		for ctx.Err() == nil {
 			time.Sleep(100 * time.Millisecond)
-			err := s.Read(chRawOut)
+			err := s.Read(ctx, chRawOut)
 			if err != nil {
 				s.logger.Error(err.Error())
 			}
@@ -103,19 +128,26 @@ func (s *iotaSubscriber) Subscribe(ctx context.Context, wg *sync.WaitGroup) bool
 	}()
 
 	wg.Add(1)
-	go func(chBytes chan []byte) {
+	go func(chPayloads chan payload) {
 		defer wg.Done()
-		for {
-			b, ok := <-chBytes
-			if !ok {
-				return
-			}
+		defer close(s.chPub)
+		for p := range chPayloads {
 			var wrapped message.SubscribeWrapper
-			err := json.Unmarshal(b, &wrapped)
+			err := json.Unmarshal(p.data, &wrapped)
 			if err != nil {
+				// A payload that isn't a SubscribeWrapper will never succeed, so without a store it counts as handled
 				s.logger.Error(err.Error())
+				if s.deadLetters == nil {
+					p.done(true)
+					continue
+				}
+				err = s.deadLetters.Write(context.Background(), documents.NewRawDeadLetter(p.data, err))
+				if err != nil {
+					s.logger.Error(err.Error())
+				}
+				p.done(err == nil)
 			} else {
-				s.chPub <- wrapped
+				s.chPub <- subscriber.Message{SubscribeWrapper: wrapped, Done: p.done}
 			}
 		}
 	}(chRawOut)
@@ -126,8 +158,6 @@ func (s *iotaSubscriber) Subscribe(ctx context.Context, wg *sync.WaitGroup) bool
 
 		<-ctx.Done()
 		s.logger.Write(logging.InfoLevel, "shutdown received")
-		cancelled = true
-		close(chRawOut)
 	}()
 	return true
 }
@@ -137,10 +167,18 @@ func (s *iotaSubscriber) connect() error {
 	transport := C.transport_client_new_from_url(C.CString(s.cfg.TangleNode.Uri()))
 	s.logger.Write(logging.DebugLevel, fmt.Sprintf("transport established %s", s.cfg.TangleNode.Uri()))
 
+	if s.state.Subscribed() {
+		err := s.resume(transport)
+		if err == nil {
+			return nil
+		}
+		s.logger.Error(fmt.Sprintf("failed to resume from %s, subscribing again: %s", s.cfg.StatePath, err.Error()))
+	}
+
 	// Generate Subscriber instance
-	cErr := C.sub_new(&s.subscriber, C.CString(s.seed), C.CString(s.cfg.Encoding), payloadLength, transport)
+	cErr := C.sub_new(&s.subscriber, C.CString(s.state.Seed), C.CString(s.cfg.Encoding), payloadLength, transport)
 	s.logger.Write(logging.DebugLevel, fmt.Sprintf(get_error(cErr)))
-	s.logger.Write(logging.DebugLevel, fmt.Sprintf("subscriber established seed=%s", s.seed))
+	s.logger.Write(logging.DebugLevel, "subscriber established")
 
 	// Process announcement message
 	rawId, err := s.getAnnouncementId(s.cfg.Provider.Uri())
@@ -160,16 +198,15 @@ func (s *iotaSubscriber) connect() error {
 		if cErr == C.ERR_OK {
 			// Fetch sub link and pk for subscription
 			var subLink *C.address_t
-			var subPk *C.public_key_t
 
 			cErr = C.sub_send_subscribe(&subLink, s.subscriber, address)
 			s.logger.Write(logging.DebugLevel, fmt.Sprintf(get_error(cErr)))
 			if cErr == C.ERR_OK {
-				cErr = C.sub_get_public_key(&subPk, s.subscriber)
+				cErr = C.sub_get_public_key(&s.publicKey, s.subscriber)
 				s.logger.Write(logging.DebugLevel, fmt.Sprintf(get_error(cErr)))
 				if cErr == C.ERR_OK {
 					subIdStr := C.get_address_id_str(subLink)
-					subPkStr := C.public_key_to_string(subPk)
+					subPkStr := C.public_key_to_string(s.publicKey)
 
 					s.logger.Write(logging.DebugLevel, fmt.Sprintf("send subscription request %s", C.GoString(subIdStr)))
 					r := subscriptionRequest{
@@ -180,6 +217,11 @@ func (s *iotaSubscriber) connect() error {
 					sendSubscriptionIdToAuthor(s.cfg.Provider.Uri(), body)
 					s.logger.Write(logging.DebugLevel, "subscription request sent")
 
+					s.state.AnnouncementId = rawId
+					s.state.SubscriptionLink = address_string(subLink)
+					s.state.PskId = C.GoString(C.pskid_as_str(pskid))
+					s.persist()
+
 					// Free generated c strings from mem
 					C.drop_str(subIdStr)
 					C.drop_str(subPkStr)
@@ -191,29 +233,141 @@ func (s *iotaSubscriber) connect() error {
 	return errors.New("failed to connect publisher")
 }
 
-func (s *iotaSubscriber) Read(chRawOut chan []byte) error {
+// resume restores the subscriber recorded in the state file instead of subscribing to the author again. The exported
+// user state carries the position of every branch, so reading continues after the last synced message. Without it the
+// subscriber is recovered from its seed and the announcement, and the channel is read again from the start.
+func (s *iotaSubscriber) resume(transport *C.transport_t) error {
+	password := C.CString(s.key)
+	defer C.free(unsafe.Pointer(password))
+
+	var cErr C.err_t
+	if len(s.state.Exported) > 0 {
+		exported := C.CBytes(s.state.Exported)
+		defer C.free(exported)
+		buffer := C.buffer_t{
+			ptr:  (*C.uint8_t)(exported),
+			size: C.size_t(len(s.state.Exported)),
+			cap:  C.size_t(len(s.state.Exported)),
+		}
+		cErr = C.sub_import(&s.subscriber, buffer, password, transport)
+	} else {
+		seed := C.CString(s.state.Seed)
+		defer C.free(unsafe.Pointer(seed))
+		announcement := C.address_from_string(C.CString(s.state.AnnouncementId))
+		defer C.drop_address(announcement)
+		cErr = C.sub_recover(&s.subscriber, seed, announcement, transport)
+		if cErr == C.ERR_OK {
+			var pskid *C.psk_id_t
+			cErr = C.sub_store_psk(&pskid, s.subscriber, password)
+		}
+	}
+	if cErr != C.ERR_OK {
+		return errors.New(get_error(cErr))
+	}
+
+	cErr = C.sub_get_public_key(&s.publicKey, s.subscriber)
+	if cErr != C.ERR_OK {
+		return errors.New(get_error(cErr))
+	}
+	s.logger.Write(logging.InfoLevel, fmt.Sprintf("resumed streams subscription %s from %s", s.state.SubscriptionLink, s.cfg.StatePath))
+	return nil
+}
+
+// persist records the subscriber's current position in the state file. In a single branch channel every cursor moves
+// to the latest message, so the subscriber's own cursor gives the last synced link.
+func (s *iotaSubscriber) persist() {
+	if len(s.cfg.StatePath) == 0 {
+		return
+	}
+
+	var userState *C.user_state_t
+	if C.sub_fetch_state(&userState, s.subscriber) == C.ERR_OK {
+		link := C.get_link_from_state(userState, s.publicKey)
+		if link != nil {
+			s.state.LastLink = address_string(link)
+		}
+		C.drop_user_state(userState)
+	}
+
+	password := C.CString(s.key)
+	defer C.free(unsafe.Pointer(password))
+	var buffer C.buffer_t
+	cErr := C.sub_export(&buffer, s.subscriber, password)
+	if cErr == C.ERR_OK {
+		s.state.Exported = C.GoBytes(unsafe.Pointer(buffer.ptr), C.int(buffer.size))
+		C.drop_buffer(buffer)
+	} else {
+		s.logger.Error(fmt.Sprintf("failed to export streams state: %s", get_error(cErr)))
+	}
+
+	err := SaveState(s.cfg.StatePath, s.state)
+	if err != nil {
+		s.logger.Error(err.Error())
+	}
+}
+
+// Read syncs with the channel and passes on the messages published since the last sync, waiting until each has been
+// handled. The position is only persisted once every message read has been ingested or dead-lettered, so messages
+// still being handled when the subscriber stops are read again once it resumes from its state file. Once a message
+// couldn't be handled the position isn't persisted again, so that it is read again on restart too.
+func (s *iotaSubscriber) Read(ctx context.Context, chRawOut chan payload) error {
 	var messages *C.unwrapped_messages_t
 	cErr := C.sub_sync_state(&messages, s.subscriber)
 	//defer C.drop_unwrapped_messages(messages)
 
-	if cErr == C.ERR_OK {
-		count := int(C.get_payloads_count(messages))
-		idx := 0
-		for idx < count {
-			msg := C.get_indexed_payload(messages, C.size_t(idx))
-			out := C.GoBytes(unsafe.Pointer(msg.masked_payload.ptr), C.int(msg.masked_payload.size))
-			fmt.Println(msg.masked_payload.ptr, msg.masked_payload.size)
-			content := string(out)
-			fmt.Println(fmt.Sprintf("Message -- len:%v txt:%s", len(out), content))
-			if len(out) > 0 { // Sometimes empty messages come across during connect handshake
-				chRawOut <- out
-			}
-			C.drop_payloads(msg)
-			idx++
-		}
-	} else {
+	if cErr != C.ERR_OK {
 		return errors.New(get_error(cErr))
 	}
+
+	var handled sync.WaitGroup
+	var failed int32
+	done := func(ok bool) {
+		if !ok {
+			atomic.StoreInt32(&failed, 1)
+		}
+		handled.Done()
+	}
+	count := int(C.get_payloads_count(messages))
+	idx := 0
+	for idx < count {
+		msg := C.get_indexed_payload(messages, C.size_t(idx))
+		out := C.GoBytes(unsafe.Pointer(msg.masked_payload.ptr), C.int(msg.masked_payload.size))
+		fmt.Println(msg.masked_payload.ptr, msg.masked_payload.size)
+		content := string(out)
+		fmt.Println(fmt.Sprintf("Message -- len:%v txt:%s", len(out), content))
+		C.drop_payloads(msg)
+		idx++
+		if len(out) == 0 { // Sometimes empty messages come across during connect handshake
+			continue
+		}
+		handled.Add(1)
+		select {
+		case chRawOut <- payload{data: out, done: done}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	chHandled := make(chan struct{})
+	go func() {
+		handled.Wait()
+		close(chHandled)
+	}()
+	select {
+	case <-chHandled:
+	case <-ctx.Done():
+		return nil
+	}
+	if atomic.LoadInt32(&failed) != 0 && !s.stalled {
+		s.stalled = true
+		s.logger.Error("streams messages could not be handled, they will be read again when the subscriber restarts")
+	}
+	if !s.stalled {
+		s.persist()
+	}
 	return nil
 }
 
@@ -262,6 +416,15 @@ func sendSubscriptionIdToAuthor(url string, body []byte) error {
 	return nil
 }
 
+// address_string formats a link the way address_from_string parses it, as the channel address and message id
+func address_string(address *C.address_t) string {
+	inst := C.get_address_inst_str(address)
+	id := C.get_address_id_str(address)
+	defer C.drop_str(inst)
+	defer C.drop_str(id)
+	return C.GoString(inst) + ":" + C.GoString(id)
+}
+
 type subscriptionRequest struct {
 	MsgId string `json:"msgid"`
 	Pk    string `json:"pk"`
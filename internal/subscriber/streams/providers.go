@@ -0,0 +1,50 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package streams
+
+import (
+	"errors"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/deadletter"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber/streams/file"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber/streams/webhook"
+)
+
+// The sources below are pure Go and always included. IOTA requires cgo and is registered by iota.go when building
+// with the iota tag. MQTT, Kafka and NATS come from the pub/sub providers.
+func init() {
+	Register(config.FileStream, newFileSubscriber)
+	Register(config.HttpStream, newWebhookSubscriber)
+}
+
+func newFileSubscriber(cfg config.StreamInfo, pub chan subscriber.Message, key string, deadLetters deadletter.Store,
+	logger logInterface.Logger) (subscriber.Subscriber, error) {
+	endpoint, ok := cfg.Config.(config.FileStreamConfig)
+	if !ok {
+		return nil, errors.New("unknown type cast to FileStreamConfig failed")
+	}
+	return file.NewFileSubscriber(endpoint, pub, logger), nil
+}
+
+func newWebhookSubscriber(cfg config.StreamInfo, pub chan subscriber.Message, key string, deadLetters deadletter.Store,
+	logger logInterface.Logger) (subscriber.Subscriber, error) {
+	endpoint, ok := cfg.Config.(config.HttpStreamConfig)
+	if !ok {
+		return nil, errors.New("unknown type cast to HttpStreamConfig failed")
+	}
+	return webhook.NewWebhookSubscriber(endpoint, pub, logger), nil
+}
@@ -0,0 +1,244 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/bootstrap"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHeader          string = "X-Alvarium-Signature"
+	defaultPath            string = "/annotations"
+	defaultTimestampHeader string = "X-Alvarium-Timestamp"
+	defaultTolerance       int64  = 300000
+	maxBodyBytes           int64  = 4 << 20
+	requestTimeout         int64  = 10000
+)
+
+// webhookSubscriber receives annotations from annotators that can only make HTTP requests. The body may be a
+// SubscribeWrapper, as published over MQTT, or a bare AnnotationList whose action is given by the "action" query
+// parameter and defaults to create.
+type webhookSubscriber struct {
+	chPub    chan subscriber.Message
+	endpoint config.HttpStreamConfig
+	logger   logInterface.Logger
+	server   *http.Server
+}
+
+func NewWebhookSubscriber(endpoint config.HttpStreamConfig, pub chan subscriber.Message, logger logInterface.Logger) subscriber.Subscriber {
+	if len(endpoint.Path) == 0 {
+		endpoint.Path = defaultPath
+	}
+	if len(endpoint.Header) == 0 {
+		endpoint.Header = defaultHeader
+	}
+	if len(endpoint.TimestampHeader) == 0 {
+		endpoint.TimestampHeader = defaultTimestampHeader
+	}
+	if endpoint.Tolerance <= 0 {
+		endpoint.Tolerance = defaultTolerance
+	}
+	return &webhookSubscriber{
+		chPub:    pub,
+		endpoint: endpoint,
+		logger:   logger,
+	}
+}
+
+func (s *webhookSubscriber) Subscribe(ctx context.Context, wg *sync.WaitGroup) bool {
+	mux := http.NewServeMux()
+	mux.Handle(s.endpoint.Path, s.handler(ctx))
+
+	timeout := time.Millisecond * time.Duration(requestTimeout)
+	s.server = &http.Server{
+		Addr:         s.endpoint.Endpoint.Host + ":" + strconv.Itoa(s.endpoint.Endpoint.Port),
+		Handler:      mux,
+		WriteTimeout: timeout,
+		ReadTimeout:  timeout,
+	}
+	// Bind before returning so that a port already in use fails the bootstrap
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		s.logger.Error(err.Error())
+		return false
+	}
+	s.logger.Write(logging.InfoLevel, "Webhook starting ("+s.server.Addr+s.endpoint.Path+")")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		err := s.server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			s.logger.Error(err.Error())
+			bootstrap.Fail(ctx, err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() { // Graceful shutdown
+		defer wg.Done()
+
+		<-ctx.Done()
+		// Requests still in flight give up on ctx, after which nothing else can send on chPub
+		_ = s.server.Shutdown(context.Background())
+		close(s.chPub)
+		s.logger.Write(logging.InfoLevel, "shutdown received")
+	}()
+	return true
+}
+
+func (s *webhookSubscriber) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+func (s *webhookSubscriber) handler(ctx context.Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		b, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			s.reject(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		timestamp := r.Header.Get(s.endpoint.TimestampHeader)
+		if !s.fresh(timestamp, time.Now()) {
+			s.reject(w, http.StatusUnauthorized, "missing or stale timestamp")
+			return
+		}
+		if !s.verify(timestamp, b, r.Header.Get(s.endpoint.Header)) {
+			s.reject(w, http.StatusUnauthorized, "invalid signature")
+			return
+		}
+		wrapped, err := parse(b, r.URL.Query().Get("action"))
+		if err != nil {
+			s.reject(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Only accept once the pipeline has taken the message, so the annotator can retry otherwise
+		select {
+		case s.chPub <- subscriber.Message{SubscribeWrapper: wrapped}:
+			w.WriteHeader(http.StatusAccepted)
+		case <-r.Context().Done():
+			s.reject(w, http.StatusServiceUnavailable, r.Context().Err().Error())
+		case <-ctx.Done():
+			s.reject(w, http.StatusServiceUnavailable, "shutting down")
+		}
+	}
+}
+
+// fresh reports whether the request was signed within the tolerance of now, so a captured request can't be replayed
+// once it has gone stale.
+func (s *webhookSubscriber) fresh(timestamp string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew <= time.Millisecond*time.Duration(s.endpoint.Tolerance)
+}
+
+// verify checks the hex encoded HMAC-SHA256 of the timestamp, a "." and the body. A "sha256=" prefix, as sent by many
+// webhook clients, is allowed.
+func (s *webhookSubscriber) verify(timestamp string, body []byte, signature string) bool {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(sig) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(s.endpoint.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+func (s *webhookSubscriber) reject(w http.ResponseWriter, status int, reason string) {
+	s.logger.Write(logging.DebugLevel, fmt.Sprintf("webhook request rejected (%v): %s", status, reason))
+	w.WriteHeader(status)
+	w.Write([]byte(reason))
+}
+
+// parse accepts either a SubscribeWrapper or an AnnotationList. The content itself is validated during ingestion.
+func parse(body []byte, action string) (message.SubscribeWrapper, error) {
+	var probe struct {
+		Action *message.SdkAction `json:"action"`
+		Items  *json.RawMessage   `json:"items"`
+	}
+	err := json.Unmarshal(body, &probe)
+	if err != nil {
+		return message.SubscribeWrapper{}, err
+	}
+
+	if probe.Action != nil {
+		var wrapped message.SubscribeWrapper
+		if err = json.Unmarshal(body, &wrapped); err != nil {
+			return message.SubscribeWrapper{}, err
+		}
+		if !supported(wrapped.Action) {
+			return message.SubscribeWrapper{}, fmt.Errorf("unsupported action %s", wrapped.Action)
+		}
+		return wrapped, nil
+	}
+	if probe.Items == nil {
+		return message.SubscribeWrapper{}, fmt.Errorf("expected a SubscribeWrapper or AnnotationList")
+	}
+	if len(action) == 0 {
+		action = string(message.ActionCreate)
+	}
+	if !supported(message.SdkAction(action)) {
+		return message.SubscribeWrapper{}, fmt.Errorf("unsupported action %s", action)
+	}
+	return message.SubscribeWrapper{
+		Action:      message.SdkAction(action),
+		MessageType: "AnnotationList",
+		Content:     body,
+	}, nil
+}
+
+// supported reports whether ingestion handles the action. Anything else would be accepted here only to be dropped.
+func supported(action message.SdkAction) bool {
+	switch action {
+	case message.ActionCreate, message.ActionMutate, message.ActionTransit:
+		return true
+	}
+	return false
+}
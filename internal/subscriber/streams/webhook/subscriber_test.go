@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"github.com/project-alvarium/scoring-apps-go/internal/subscriber"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	chMessages := make(chan subscriber.Message, 1)
+	s := NewWebhookSubscriber(config.HttpStreamConfig{Secret: "secret"}, chMessages, logger).(*webhookSubscriber)
+	handler := s.handler(context.Background())
+
+	list := `{"items":[]}`
+	wrapper := `{"action":"transit","content":"e30="}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	tests := []struct {
+		name      string
+		body      string
+		query     string
+		timestamp string
+		signature string
+		status    int
+		action    message.SdkAction
+	}{
+		{"annotation list", list, "", now, sign(now, list, "secret"), http.StatusAccepted, message.ActionCreate},
+		{"annotation list with action", list, "?action=mutate", now, "sha256=" + sign(now, list, "secret"), http.StatusAccepted, message.ActionMutate},
+		{"subscribe wrapper", wrapper, "", now, sign(now, wrapper, "secret"), http.StatusAccepted, message.ActionTransit},
+		{"wrong secret", list, "", now, sign(now, list, "other"), http.StatusUnauthorized, ""},
+		{"missing signature", list, "", now, "", http.StatusUnauthorized, ""},
+		{"missing timestamp", list, "", "", sign("", list, "secret"), http.StatusUnauthorized, ""},
+		{"stale timestamp", list, "", stale, sign(stale, list, "secret"), http.StatusUnauthorized, ""},
+		{"timestamp not signed", list, "", now, sign(stale, list, "secret"), http.StatusUnauthorized, ""},
+		{"unknown action", list, "?action=bogus", now, sign(now, list, "secret"), http.StatusBadRequest, ""},
+		{"publish action", list, "?action=publish", now, sign(now, list, "secret"), http.StatusBadRequest, ""},
+		{"publish wrapper", `{"action":"publish","content":"e30="}`, "", now, sign(now, `{"action":"publish","content":"e30="}`, "secret"), http.StatusBadRequest, ""},
+		{"unknown body", `{"foo":1}`, "", now, sign(now, `{"foo":1}`, "secret"), http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, defaultPath+tt.query, strings.NewReader(tt.body))
+			req.Header.Set(defaultHeader, tt.signature)
+			req.Header.Set(defaultTimestampHeader, tt.timestamp)
+			w := httptest.NewRecorder()
+			handler(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %v, got %v", tt.status, w.Code)
+			}
+			if tt.status != http.StatusAccepted {
+				return
+			}
+			m := <-chMessages
+			if m.Action != tt.action {
+				t.Errorf("expected action %s, got %s", tt.action, m.Action)
+			}
+		})
+	}
+}
+
+func sign(timestamp string, body string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
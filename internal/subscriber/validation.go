@@ -0,0 +1,189 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/oklog/ulid/v2"
+	sdkContract "github.com/project-alvarium/alvarium-sdk-go/pkg/contracts"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logInterface "github.com/project-alvarium/provider-logging/pkg/interfaces"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"strings"
+)
+
+// ValidationError is returned when an AnnotationList is rejected. It is never retried since the same payload will
+// always be rejected for the same reasons.
+type ValidationError struct {
+	Reasons []string
+}
+
+func (e ValidationError) Error() string {
+	return "annotation list rejected: " + strings.Join(e.Reasons, "; ")
+}
+
+// Validator parses incoming payloads into AnnotationLists and checks them before anything is written to the graph.
+type Validator struct {
+	logger logInterface.Logger
+	mode   config.ValidationMode
+}
+
+func NewValidator(cfg config.ValidationInfo, logger logInterface.Logger) Validator {
+	mode := cfg.Mode
+	if len(mode) == 0 {
+		mode = config.ValidationLenient
+	}
+	return Validator{
+		logger: logger,
+		mode:   mode,
+	}
+}
+
+// Validators selects the Validator for the stream a message was received from, falling back to the subscriber's own
+// validation config for streams that don't set one.
+type Validators struct {
+	bySource map[string]Validator
+	fallback Validator
+}
+
+func NewValidators(fallback config.ValidationInfo, sources []config.SourceInfo, logger logInterface.Logger) Validators {
+	v := Validators{
+		bySource: make(map[string]Validator),
+		fallback: NewValidator(fallback, logger),
+	}
+	for _, s := range sources {
+		if len(s.Validation.Mode) > 0 {
+			v.bySource[s.Name] = NewValidator(s.Validation, logger)
+		}
+	}
+	return v
+}
+
+// For returns the Validator for messages from the named source
+func (v Validators) For(source string) Validator {
+	if validator, ok := v.bySource[source]; ok {
+		return validator
+	}
+	return v.fallback
+}
+
+// Validate returns the annotations from content that may be ingested for the given action. In lenient mode invalid
+// annotations are logged and left out of the result.
+func (v Validator) Validate(action message.SdkAction, content []byte) (sdkContract.AnnotationList, error) {
+	// Items are unmarshaled one at a time so that a single bad annotation can be reported, or dropped, on its own
+	var raw struct {
+		Items []json.RawMessage `json:"items,omitempty"`
+	}
+	err := json.Unmarshal(content, &raw)
+	if err != nil {
+		return sdkContract.AnnotationList{}, ValidationError{Reasons: []string{err.Error()}}
+	}
+
+	var list sdkContract.AnnotationList
+	var reasons []string
+	for i, b := range raw.Items {
+		var a sdkContract.Annotation
+		err := json.Unmarshal(b, &a)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("item %v: %s", i, err.Error()))
+			continue
+		}
+		invalid := validateAnnotation(a)
+		for _, r := range invalid {
+			reasons = append(reasons, fmt.Sprintf("item %v: %s", i, r))
+		}
+		if len(invalid) == 0 {
+			list.Items = append(list.Items, a)
+		}
+	}
+	if len(reasons) > 0 {
+		if v.mode == config.ValidationStrict {
+			return sdkContract.AnnotationList{}, ValidationError{Reasons: reasons}
+		}
+		v.logger.Write(logging.WarnLevel, fmt.Sprintf("dropped invalid annotations: %s", strings.Join(reasons, "; ")))
+	}
+
+	if len(list.Items) == 0 {
+		if len(raw.Items) == 0 {
+			return list, ValidationError{Reasons: []string{"annotation list is empty"}}
+		}
+		return list, ValidationError{Reasons: append(reasons, "no valid annotations remain")}
+	}
+
+	reasons = nil
+	switch action {
+	case message.ActionCreate, message.ActionTransit:
+		for _, a := range list.Items {
+			if a.Key != list.Items[0].Key {
+				reasons = append(reasons, fmt.Sprintf("%s annotations refer to more than one key", action))
+				break
+			}
+		}
+	case message.ActionMutate:
+		reasons = validateMutate(list)
+	}
+	if len(reasons) > 0 {
+		return sdkContract.AnnotationList{}, ValidationError{Reasons: reasons}
+	}
+	return list, nil
+}
+
+func validateAnnotation(a sdkContract.Annotation) []string {
+	var reasons []string
+	if a.Id == (ulid.ULID{}) {
+		reasons = append(reasons, "missing id")
+	}
+	if len(a.Key) == 0 {
+		reasons = append(reasons, "missing key")
+	}
+	if len(a.Host) == 0 {
+		reasons = append(reasons, "missing host")
+	}
+	if a.Timestamp.IsZero() {
+		reasons = append(reasons, "missing timestamp")
+	}
+	return reasons
+}
+
+// validateMutate checks that the list links exactly one previous version, the src annotation, to one new version.
+func validateMutate(list sdkContract.AnnotationList) []string {
+	var src, target string
+	sources := 0
+	for _, a := range list.Items {
+		if a.Kind == sdkContract.AnnotationSource {
+			src = a.Key
+			sources++
+		} else if len(target) == 0 {
+			target = a.Key
+		} else if a.Key != target {
+			return []string{"mutate annotations refer to more than one new key"}
+		}
+	}
+
+	var reasons []string
+	if sources == 0 {
+		reasons = append(reasons, "mutate has no src annotation")
+	} else if sources > 1 {
+		reasons = append(reasons, "mutate has more than one src annotation")
+	}
+	if len(target) == 0 {
+		reasons = append(reasons, "mutate has no annotations for the new key")
+	} else if src == target {
+		reasons = append(reasons, "mutate src and new key are the same")
+	}
+	return reasons
+}
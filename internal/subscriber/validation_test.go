@@ -0,0 +1,110 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package subscriber
+
+import (
+	"encoding/json"
+	"errors"
+	sdkContract "github.com/project-alvarium/alvarium-sdk-go/pkg/contracts"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
+	logConfig "github.com/project-alvarium/provider-logging/pkg/config"
+	logFactory "github.com/project-alvarium/provider-logging/pkg/factories"
+	"github.com/project-alvarium/provider-logging/pkg/logging"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	strict := NewValidator(config.ValidationInfo{Mode: config.ValidationStrict}, logger)
+	lenient := NewValidator(config.ValidationInfo{}, logger)
+
+	valid := sdkContract.NewAnnotation("key", sdkContract.SHA256Hash, "host", sdkContract.AnnotationTPM, true)
+	noKey := sdkContract.NewAnnotation("", sdkContract.SHA256Hash, "host", sdkContract.AnnotationTLS, true)
+	src := sdkContract.NewAnnotation("old", sdkContract.SHA256Hash, "host", sdkContract.AnnotationSource, true)
+	other := sdkContract.NewAnnotation("other", sdkContract.SHA256Hash, "host", sdkContract.AnnotationTPM, true)
+	unknownKind := `{"id":"01G2YJ1Y7PCZ6G7P3ZQAN1Q2RH","key":"key","hash":"sha256","host":"host","kind":"bogus","timestamp":"2022-05-10T00:00:00Z"}`
+
+	tests := []struct {
+		name      string
+		validator Validator
+		action    message.SdkAction
+		content   string
+		items     int
+		reject    bool
+	}{
+		{"valid create", strict, message.ActionCreate, list(valid), 1, false},
+		{"strict missing key", strict, message.ActionCreate, list(valid, noKey), 0, true},
+		{"lenient missing key", lenient, message.ActionCreate, list(valid, noKey), 1, false},
+		{"strict unknown kind", strict, message.ActionCreate, `{"items":[` + marshal(valid) + `,` + unknownKind + `]}`, 0, true},
+		{"lenient unknown kind", lenient, message.ActionCreate, `{"items":[` + marshal(valid) + `,` + unknownKind + `]}`, 1, false},
+		{"zero timestamp", lenient, message.ActionCreate, list(sdkContract.Annotation{Id: valid.Id, Key: "key", Host: "host", Hash: sdkContract.SHA256Hash, Kind: sdkContract.AnnotationTPM}), 0, true},
+		{"empty list", lenient, message.ActionCreate, `{"items":[]}`, 0, true},
+		{"not json", lenient, message.ActionCreate, `{`, 0, true},
+		{"create with several keys", lenient, message.ActionTransit, list(valid, other), 0, true},
+		{"valid mutate", strict, message.ActionMutate, list(src, valid), 2, false},
+		{"mutate without src", lenient, message.ActionMutate, list(valid), 0, true},
+		{"mutate with only src", lenient, message.ActionMutate, list(src), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.validator.Validate(tt.action, []byte(tt.content))
+			if tt.reject {
+				if !errors.As(err, &ValidationError{}) {
+					t.Fatalf("expected a ValidationError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(result.Items) != tt.items {
+				t.Errorf("expected %v items, got %v", tt.items, len(result.Items))
+			}
+		})
+	}
+}
+
+func TestValidatorsBySource(t *testing.T) {
+	logger := logFactory.NewLogger(logConfig.LoggingInfo{MinLogLevel: logging.ErrorLevel})
+	sources := []config.SourceInfo{
+		{Name: "site-a", Validation: config.ValidationInfo{Mode: config.ValidationStrict}},
+		{Name: "site-b"},
+	}
+	validators := NewValidators(config.ValidationInfo{Mode: config.ValidationLenient}, sources, logger)
+
+	valid := sdkContract.NewAnnotation("key", sdkContract.SHA256Hash, "host", sdkContract.AnnotationTPM, true)
+	noKey := sdkContract.NewAnnotation("", sdkContract.SHA256Hash, "host", sdkContract.AnnotationTLS, true)
+	content := []byte(list(valid, noKey))
+
+	if _, err := validators.For("site-a").Validate(message.ActionCreate, content); err == nil {
+		t.Errorf("expected the stream's own strict mode to reject the list")
+	}
+	for _, source := range []string{"site-b", "unknown"} {
+		if _, err := validators.For(source).Validate(message.ActionCreate, content); err != nil {
+			t.Errorf("expected %s to fall back to lenient mode, got %v", source, err)
+		}
+	}
+}
+
+func list(items ...sdkContract.Annotation) string {
+	b, _ := json.Marshal(sdkContract.AnnotationList{Items: items})
+	return string(b)
+}
+
+func marshal(a sdkContract.Annotation) string {
+	b, _ := json.Marshal(a)
+	return string(b)
+}
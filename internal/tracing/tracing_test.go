@@ -0,0 +1,57 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package tracing
+
+import (
+	"context"
+	"github.com/project-alvarium/scoring-apps-go/internal/config"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func TestPropagation(t *testing.T) {
+	shutdown, err := Init(context.Background(), config.TracingInfo{}, "test")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer shutdown(context.Background())
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, publish := Start(context.Background(), SpanPublish)
+	headers := Inject(ctx)
+	publish.End()
+	if len(headers["traceparent"]) == 0 {
+		t.Fatalf("no traceparent in %v", headers)
+	}
+
+	// The consumer only has the headers to go on
+	_, collect := Start(Extract(context.Background(), headers), SpanCollect)
+	collect.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %v", len(spans))
+	}
+	if spans[1].Parent().SpanID() != spans[0].SpanContext().SpanID() || !spans[1].Parent().IsRemote() {
+		t.Errorf("collect span is not a remote child of publish")
+	}
+	if Inject(context.Background()) != nil {
+		t.Errorf("expected no headers without a span")
+	}
+}
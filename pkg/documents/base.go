@@ -15,8 +15,11 @@
 package documents
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/oklog/ulid/v2"
 	"math/rand"
+	"sort"
 	"time"
 )
 
@@ -33,3 +36,24 @@ func NewULID() ulid.ULID {
 
 	return id
 }
+
+// NewScoreKey derives a deterministic key for a score from the data it scores, the policy applied and the annotations
+// considered. Scoring the same annotations under the same policy twice, for example because a CalculateScore message
+// was delivered more than once, therefore produces the same key and the write can be treated as an upsert.
+func NewScoreKey(dataRef string, policyFingerprint string, annotations []Annotation) string {
+	ids := make([]string, len(annotations))
+	for i, a := range annotations {
+		ids[i] = a.Key
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	h.Write([]byte(dataRef))
+	h.Write([]byte{0})
+	h.Write([]byte(policyFingerprint))
+	for _, id := range ids {
+		h.Write([]byte{0})
+		h.Write([]byte(id))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -15,8 +15,8 @@
 package documents
 
 import (
-	"github.com/oklog/ulid/v2"
 	"github.com/project-alvarium/alvarium-sdk-go/pkg/contracts"
+	"github.com/project-alvarium/alvarium-sdk-go/pkg/message"
 	"github.com/project-alvarium/scoring-apps-go/pkg/policies"
 	"math"
 	"time"
@@ -47,6 +47,7 @@ type Annotation struct {
 	Signature   string             `json:"signature,omitempty"` // Signature contains the signature of the party making the annotation
 	IsSatisfied bool               `json:"isSatisfied"`         // IsSatisfied indicates whether the criteria defining the annotation were fulfilled
 	Timestamp   time.Time          `json:"timestamp,omitempty"` // Timestamp indicates when the annotation was created
+	Source      string             `json:"source,omitempty"`    // Source names the stream the annotation was received from
 }
 
 // NewAnnotation will map an Alvarium SDK annotation into an Annotation document
@@ -65,13 +66,15 @@ func NewAnnotation(a contracts.Annotation) Annotation {
 
 // Score represents a document in the "score" vertex collection
 type Score struct {
-	Key        ulid.ULID `json:"_key,omitempty"`       // Key uniquely identifies the document in the database
-	DataRef    string    `json:"dataRef,omitempty"`    // DataRef points to the key of the data being annotated
-	Passed     int       `json:"score,omitempty"`      // Passed indicates how many of the annotations for a given dataRef were Satisfied
-	Count      int       `json:"count,omitempty"`      // Count indicates the total number of annotations applicable to a dataRef
-	Policy     string    `json:"policy,omitempty"`     // Policy will indicate some version of the policy used to calculate confidence
-	Confidence float64   `json:"confidence,omitempty"` // Confidence is the percentage of trust in the dataRef
-	Timestamp  time.Time `json:"timestamp,omitempty"`  // Timestamp indicates when the score was calculated
+	Key         string            `json:"_key,omitempty"`        // Key uniquely identifies the document in the database, see NewScoreKey
+	DataRef     string            `json:"dataRef,omitempty"`     // DataRef points to the key of the data being annotated
+	Passed      int               `json:"score,omitempty"`       // Passed indicates how many of the annotations for a given dataRef were Satisfied
+	Count       int               `json:"count,omitempty"`       // Count indicates the total number of annotations applicable to a dataRef
+	Policy      string            `json:"policy,omitempty"`      // Policy will indicate some version of the policy used to calculate confidence
+	Fingerprint string            `json:"fingerprint,omitempty"` // Fingerprint identifies the exact weights of the policy used
+	Confidence  float64           `json:"confidence,omitempty"`  // Confidence is the percentage of trust in the dataRef
+	Timestamp   time.Time         `json:"timestamp,omitempty"`   // Timestamp indicates when the score was calculated
+	Trace       map[string]string `json:"trace,omitempty"`       // Trace is the trace context of the scoring, continued when the confidence is populated
 }
 
 func NewScore(dataRef string, annotations []Annotation, policy policies.DcfPolicy) Score {
@@ -89,32 +92,76 @@ func NewScore(dataRef string, annotations []Annotation, policy policies.DcfPolic
 	confidence := float64(passedWeight / totalWeight)
 	confidence = math.Round(confidence*100) / 100
 
+	fingerprint := policy.Fingerprint()
 	s := Score{
-		Key:        NewULID(),
-		DataRef:    dataRef,
-		Passed:     passed,
-		Count:      len(annotations),
-		Policy:     policy.Name,
-		Confidence: confidence,
-		Timestamp:  time.Now(),
+		Key:         NewScoreKey(dataRef, fingerprint, annotations),
+		DataRef:     dataRef,
+		Passed:      passed,
+		Count:       len(annotations),
+		Policy:      policy.Name,
+		Fingerprint: fingerprint,
+		Confidence:  confidence,
+		Timestamp:   time.Now(),
 	}
 	return s
 }
 
+// DeadLetter records a message the subscriber failed to ingest so it can be inspected and re-driven later
+type DeadLetter struct {
+	Key       string                   `json:"_key,omitempty"`      // Key uniquely identifies the dead letter
+	Message   message.SubscribeWrapper `json:"message,omitempty"`   // Message is the message that failed ingestion, if it could be unmarshaled
+	Source    string                   `json:"source,omitempty"`    // Source names the stream Message was received from
+	Raw       []byte                   `json:"raw,omitempty"`       // Raw is the payload as received when it could not be unmarshaled
+	Error     string                   `json:"error,omitempty"`     // Error describes the last failure
+	Attempts  int                      `json:"attempts,omitempty"`  // Attempts is the number of times ingestion has been attempted
+	Timestamp time.Time                `json:"timestamp,omitempty"` // Timestamp indicates when the message was last dead-lettered
+}
+
+// NewDeadLetter creates a DeadLetter for a message that could not be ingested
+func NewDeadLetter(msg message.SubscribeWrapper, err error, attempts int) DeadLetter {
+	return DeadLetter{
+		Key:       NewULID().String(),
+		Message:   msg,
+		Error:     err.Error(),
+		Attempts:  attempts,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewRawDeadLetter creates a DeadLetter for a payload that could not be unmarshaled into a message
+func NewRawDeadLetter(raw []byte, err error) DeadLetter {
+	return DeadLetter{
+		Key:       NewULID().String(),
+		Raw:       raw,
+		Error:     err.Error(),
+		Attempts:  1,
+		Timestamp: time.Now(),
+	}
+}
+
+// Member represents a calculator replica in the sharding membership collection
+type Member struct {
+	Key       string    `json:"_key,omitempty"`      // Key is the replica's instance id
+	Timestamp time.Time `json:"timestamp,omitempty"` // Timestamp indicates when the replica last sent a heartbeat
+}
+
 // Trust represents a document in the "trust" edge collection
 type Trust struct {
+	Key  string `json:"_key,omitempty"` // Key matches the key of the annotation the edge points to
 	From string `json:"_from"`
 	To   string `json:"_to"`
 }
 
 // Lineage represents a document in the "lineage" edge collection
 type Lineage struct {
+	Key  string `json:"_key,omitempty"` // Key is derived from the keys of the two data versions the edge connects
 	From string `json:"_from"`
 	To   string `json:"_to"`
 }
 
 // Scoring represents a document in the "scoring" edge collection
 type Scoring struct {
+	Key  string `json:"_key,omitempty"` // Key matches the key of the score so a score is never linked twice
 	From string `json:"_from"`
 	To   string `json:"_to"`
 }
@@ -0,0 +1,60 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package documents
+
+import (
+	"github.com/project-alvarium/scoring-apps-go/pkg/policies"
+	"testing"
+)
+
+func TestNewScoreKey(t *testing.T) {
+	a1 := Annotation{Key: "01FY0000000000000000000001", Kind: "tpm", IsSatisfied: true}
+	a2 := Annotation{Key: "01FY0000000000000000000002", Kind: "tls"}
+	a3 := Annotation{Key: "01FY0000000000000000000003", Kind: "pki"}
+
+	policy := policies.DcfPolicy{
+		Name:    "default",
+		Weights: []policies.Weight{{AnnotationKey: "tpm", Value: 2}, {AnnotationKey: "tls", Value: 1}},
+	}
+	reordered := policies.DcfPolicy{
+		Name:    "default",
+		Weights: []policies.Weight{{AnnotationKey: "tls", Value: 1}, {AnnotationKey: "tpm", Value: 2}},
+	}
+	changed := policies.DcfPolicy{
+		Name:    "default",
+		Weights: []policies.Weight{{AnnotationKey: "tpm", Value: 3}, {AnnotationKey: "tls", Value: 1}},
+	}
+
+	base := NewScore("data", []Annotation{a1, a2}, policy)
+	tests := []struct {
+		name       string
+		score      Score
+		expectSame bool
+	}{
+		{"same inputs", NewScore("data", []Annotation{a1, a2}, policy), true},
+		{"annotation order", NewScore("data", []Annotation{a2, a1}, policy), true},
+		{"weight order", NewScore("data", []Annotation{a1, a2}, reordered), true},
+		{"different data", NewScore("other", []Annotation{a1, a2}, policy), false},
+		{"additional annotation", NewScore("data", []Annotation{a1, a2, a3}, policy), false},
+		{"different weights", NewScore("data", []Annotation{a1, a2}, changed), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.score.Key == base.Key) != tt.expectSame {
+				t.Errorf("expected same key %v, received %s and %s", tt.expectSame, base.Key, tt.score.Key)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package msg
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"github.com/oklog/ulid/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SchemaVersion is the envelope version published by this build. Minor versions only add fields, so a consumer
+	// accepts any minor version of a major it supports. Messages without a version predate the envelope, major 0.
+	SchemaVersion = "1.0"
+
+	ContentTypeJson = "application/json"
+	ContentTypeText = "text/plain" // ContentTypeText content is sent as bytes, such as a CalculateScore key
+)
+
+// UnsupportedVersionError is returned by Open for a message whose major version the consumer doesn't support.
+type UnsupportedVersionError struct {
+	Version   string
+	Supported []int
+}
+
+func (e UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("unsupported message schema version %s, supported major versions are %v", e.Version, e.Supported)
+}
+
+// NewPublishWrapper stamps content with the current schema version, a new message id and the time.
+func NewPublishWrapper(messageType string, contentType string, producer string, content interface{}) PublishWrapper {
+	now := time.Now()
+	return PublishWrapper{
+		SchemaVersion: SchemaVersion,
+		MessageType:   messageType,
+		ContentType:   contentType,
+		MessageId:     ulid.MustNew(ulid.Timestamp(now), rand.Reader).String(),
+		Producer:      producer,
+		Timestamp:     now,
+		Content:       content,
+	}
+}
+
+// Open decodes a payload published as a PublishWrapper, rejecting it unless its major version is one of supported.
+func Open(payload []byte, supported ...int) (SubscribeWrapper, error) {
+	var w SubscribeWrapper
+	err := json.Unmarshal(payload, &w)
+	if err != nil {
+		return w, err
+	}
+	major, err := MajorVersion(w.SchemaVersion)
+	if err != nil {
+		return w, err
+	}
+	for _, s := range supported {
+		if s == major {
+			return w, nil
+		}
+	}
+	return w, UnsupportedVersionError{Version: w.SchemaVersion, Supported: supported}
+}
+
+// MajorVersion parses the major part of a "major.minor" schema version. An empty version is major 0.
+func MajorVersion(version string) (int, error) {
+	if len(version) == 0 {
+		return 0, nil
+	}
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil || major < 0 {
+		return 0, fmt.Errorf("invalid message schema version %s", version)
+	}
+	return major, nil
+}
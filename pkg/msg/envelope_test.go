@@ -0,0 +1,73 @@
+/*******************************************************************************
+ * Copyright 2022 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package msg
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	published, _ := json.Marshal(NewPublishWrapper("CalculateScore", ContentTypeText, "subscriber", []byte("key")))
+
+	tests := []struct {
+		name    string
+		payload []byte
+		major   int
+		wantErr bool
+	}{
+		{"current", published, 1, false},
+		{"legacy", []byte(`{"messageType":"CalculateScore","content":"a2V5"}`), 0, false},
+		{"newer minor", []byte(`{"schemaVersion":"1.7","messageType":"CalculateScore","content":"a2V5","extra":true}`), 1, false},
+		{"unknown major", []byte(`{"schemaVersion":"2.0","messageType":"CalculateScore","content":"a2V5"}`), 2, true},
+		{"invalid version", []byte(`{"schemaVersion":"v1","content":"a2V5"}`), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := Open(tt.payload, 0, 1)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if major, _ := MajorVersion(w.SchemaVersion); major != tt.major || string(w.Content) != "key" {
+				t.Errorf("unexpected message %+v", w)
+			}
+		})
+	}
+
+	_, err := Open([]byte(`{"schemaVersion":"2.0"}`), 0, 1)
+	if !errors.As(err, &UnsupportedVersionError{}) {
+		t.Errorf("expected UnsupportedVersionError, got %v", err)
+	}
+}
+
+// Calculators built before the envelope only read messageType and content
+func TestLegacyConsumer(t *testing.T) {
+	published, _ := json.Marshal(NewPublishWrapper("CalculateScore", ContentTypeText, "subscriber", []byte("key")))
+	var legacy struct {
+		MessageType string `json:"messageType,omitempty"`
+		Content     []byte `json:"content,omitempty"`
+	}
+	err := json.Unmarshal(published, &legacy)
+	if err != nil || legacy.MessageType != "CalculateScore" || string(legacy.Content) != "key" {
+		t.Errorf("unexpected legacy decoding %+v %v", legacy, err)
+	}
+}
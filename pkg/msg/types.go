@@ -14,12 +14,35 @@
 
 package msg
 
+import "time"
+
+// PublishWrapper is the envelope every message is published in. Only MessageType and Content predate schema version 1,
+// so a consumer built before the envelope still reads them.
 type PublishWrapper struct {
-	MessageType string      `json:"messageType,omitempty"`
-	Content     interface{} `json:"content,omitempty"`
+	SchemaVersion string            `json:"schemaVersion,omitempty"` // SchemaVersion is "major.minor" of the envelope and its content
+	MessageType   string            `json:"messageType,omitempty"`
+	ContentType   string            `json:"contentType,omitempty"` // ContentType is the media type of Content once decoded
+	MessageId     string            `json:"messageId,omitempty"`   // MessageId is a ULID unique to the message
+	Producer      string            `json:"producer,omitempty"`    // Producer names the application that published the message
+	Timestamp     time.Time         `json:"timestamp,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Content       interface{}       `json:"content,omitempty"`
 }
 
+// SubscribeWrapper is a PublishWrapper as decoded by a consumer, see Open.
 type SubscribeWrapper struct {
-	MessageType string `json:"messageType,omitempty"`
-	Content     []byte `json:"content,omitempty"`
+	SchemaVersion string            `json:"schemaVersion,omitempty"`
+	MessageType   string            `json:"messageType,omitempty"`
+	ContentType   string            `json:"contentType,omitempty"`
+	MessageId     string            `json:"messageId,omitempty"`
+	Producer      string            `json:"producer,omitempty"`
+	Timestamp     time.Time         `json:"timestamp,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Content       []byte            `json:"content,omitempty"`
+}
+
+// Delivery is a message as received from a pub/sub provider, before its payload has been decoded.
+type Delivery struct {
+	Payload []byte
+	Ack     func() error // Ack is set by providers that expect the message to be acknowledged once handled
 }
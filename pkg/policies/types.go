@@ -15,7 +15,11 @@
 package policies
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"sort"
 )
 
 // DcfPolicy is a struct for defining behaviors of the DCF
@@ -41,6 +45,24 @@ func (p *DcfPolicy) FetchWeight(key string) Weight {
 	return w
 }
 
+// Fingerprint returns a digest of the policy's name and weights. The order in which the weights were defined does not
+// affect the result, so a policy fetched twice from the same source always yields the same fingerprint.
+func (p *DcfPolicy) Fingerprint() string {
+	weights := make([]string, len(p.Weights))
+	for i, w := range p.Weights {
+		weights[i] = fmt.Sprintf("%s=%v", w.AnnotationKey, w.Value)
+	}
+	sort.Strings(weights)
+
+	h := sha256.New()
+	h.Write([]byte(p.Name))
+	for _, w := range weights {
+		h.Write([]byte{0})
+		h.Write([]byte(w))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // Weight defines the weighting given to an individual annotation result, used when calculating a confidence score
 type Weight struct {
 	AnnotationKey string `json:"key,omitempty"`   // AnnotationKey indicates the applicable annotation type
@@ -14,7 +14,10 @@
 
 package requests
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"github.com/project-alvarium/scoring-apps-go/pkg/documents"
+)
 
 type OpaWeightsRequest struct {
 	Classifier string `json:"class,omitempty"`
@@ -34,3 +37,15 @@ func (r *OpaWeightsRequest) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(&requestAlias)
 }
+
+// EnqueueRequest asks the calculator to score the given keys as soon as a worker is available
+type EnqueueRequest struct {
+	Keys []string `json:"keys,omitempty"`
+}
+
+// WhatIfRequest asks the calculator to score the given annotations under the named policy without persisting the result
+type WhatIfRequest struct {
+	Classifier  string                 `json:"classifier,omitempty"`
+	DataRef     string                 `json:"dataRef,omitempty"`
+	Annotations []documents.Annotation `json:"annotations,omitempty"`
+}
@@ -45,6 +45,11 @@ type AnnotationListResponse struct {
 	Annotations []documents.Annotation `json:"annotations"`
 }
 
+type ScoreListResponse struct {
+	Count  int               `json:"count"`
+	Scores []documents.Score `json:"scores"`
+}
+
 type DocumentCountResponse struct {
 	Count int `json:"count"`
 }
@@ -8,20 +8,29 @@ function cleanup {
   pkill ones-demo
 }
 
+# Wait until the service whose health endpoint is on the given port reports ready
+function wait_ready {
+  for _ in $(seq 1 30); do
+    curl -sf -o /dev/null "http://localhost:$1/health/ready" && return
+    sleep 1
+  done
+  echo "service on port $1 is not ready" >&2
+}
+
 cd $CMD/subscriber
 exec -a dcf-subscriber ./subscriber-go -cfg=./res/config-mqtt.json &
 cd $DIR
-sleep 3
+wait_ready 9101
 
 cd $CMD/calculator
 exec -a dcf-calculator ./calculator-go -cfg=./res/config-mqtt.json -mode=default &
 cd $DIR
-sleep 1
+wait_ready 9102
 
 cd $CMD/populator
 exec -a dcf-populator ./populator-go -cfg ./res/config.json &
 cd $DIR
-sleep 1
+wait_ready 9103
 
 cd $CMD/populator-api
 exec -a dcf-populator-api ./populator-api-go -cfg ./res/config.json &